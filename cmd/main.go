@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"jacob/dusk/pkg/eval"
 	"jacob/dusk/pkg/repl"
 	"jacob/dusk/pkg/run"
 	"os"
@@ -14,13 +15,14 @@ func main() {
 		for restart {
 			restart = repl.Run(os.Stdin, os.Stdout)
 		}
-	case 2: // run file
+	default: // run file. any other args are passed to the script
 		file, err := os.Open(os.Args[1])
 		if err != nil {
 			fmt.Println("Error reading file:", err)
 			return
 		}
 		defer file.Close()
-		run.Run(file, os.Stdout, os.Args[1])
+		eval.Args = os.Args[2:]
+		run.Run(file, os.Stdout, os.Args[1], nil)
 	}
 }
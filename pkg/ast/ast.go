@@ -13,11 +13,20 @@ type Program struct {
 	Statements []Statement
 }
 
-// LetStatement ::= 'let' Identifier '=' Expression
+// LetStatement ::= ('let' | 'const') (Identifier | ArrayPattern) '=' Expression
 type LetStatement struct {
-	Token token.Token // token.Let
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // token.Let or token.Const
+	Name    *Identifier
+	Pattern *ArrayPattern // set instead of Name when destructuring
+	Value   Expression
+}
+
+// ClassStatement ::= 'class' Identifier ('<' Identifier)? '{' LetStatement* '}'
+type ClassStatement struct {
+	Token  token.Token // token.Class
+	Name   *Identifier
+	Parent *Identifier // optional class to inherit from
+	Fields []*LetStatement
 }
 
 // ReturnStatement ::= 'ret' expression
@@ -42,11 +51,51 @@ type WhileExpression struct {
 	Do    *BlockStatement
 }
 
-// FunctionLiteral ::= '|' (Identifier | (Identifier ',')?)* ('{' | ':')? BlockStatement '}'?
+// ForExpression ::= 'for' (Identifier | ArrayPattern) 'in' Expression ('{' | ':') BlockStatement '}'?
+type ForExpression struct {
+	Token token.Token // token.For
+	Var   Expression  // Identifier or ArrayPattern bound to each item
+	Iter  Expression
+	Do    *BlockStatement
+}
+
+// MatchExpression ::= 'match' Expression '{' (MatchArm (',' | ';'))* '}'
+type MatchExpression struct {
+	Token token.Token // token.Match
+	Value Expression
+	Arms  []*MatchArm
+}
+
+// MatchArm ::= Pattern ('if' Expression)? '=>' ('{' BlockStatement '}' | Statement)
+// Pattern ::= '_' | Identifier | Literal | RangePattern | ArrayPattern
+type MatchArm struct {
+	Token   token.Token // token.Arrow
+	Pattern Expression
+	Guard   Expression // optional condition checked after the pattern matches
+	Body    *BlockStatement
+}
+
+// RangePattern ::= Literal '..' Literal
+type RangePattern struct {
+	Token token.Token // token.Range
+	Low   Expression
+	High  Expression
+}
+
+// FunctionLiteral ::= '|' ((Identifier | ArrayPattern) ('=' Expression)? ',')* ('...' Identifier)? '|' ('{' | ':')? BlockStatement '}'?
 type FunctionLiteral struct {
-	Token  token.Token // The first '|' bar token
-	Params []*Identifier
-	Body   *BlockStatement
+	Token     token.Token  // The first '|' bar token
+	Params    []Expression // Identifier or ArrayPattern
+	Defaults  []Expression // default value for each param. nil if required
+	Rest      *Identifier  // optional trailing '...name' collecting extra args
+	Body      *BlockStatement
+	Generator bool // the body yields so calling it makes an iterator
+}
+
+// YieldExpression ::= 'yield' Expression
+type YieldExpression struct {
+	Token token.Token // token.Yield
+	Value Expression
 }
 
 // BlockStatement ::= Statement*
@@ -62,6 +111,19 @@ type CallExpression struct {
 	Args  []Expression
 }
 
+// NamedArgument ::= Identifier ':' Expression
+type NamedArgument struct {
+	Token token.Token // token.Identifier
+	Name  *Identifier
+	Value Expression
+}
+
+// SpreadExpression ::= '...' Expression
+type SpreadExpression struct {
+	Token token.Token // token.Ellipsis
+	Value Expression
+}
+
 // ExpressionStatement ::= (IntegerLiteral | FloatLiteral | StringLiteral | Operator | Identifier | PrefixExpression | InfixExpression | IndexExpression) Expression?
 type ExpressionStatement struct {
 	Token      token.Token // first token of the expression
@@ -136,3 +198,20 @@ type IndexExpression struct {
 	Left  Expression
 	Index Expression
 }
+
+// SliceExpression ::= Expression '[' Expression? ':' Expression? (':' Expression?)? ']'
+type SliceExpression struct {
+	Token token.Token // token.LBracket
+	Left  Expression
+	Start Expression // nil when omitted
+	End   Expression // nil when omitted
+	Step  Expression // nil when omitted
+}
+
+// ArrayPattern ::= '[' ((Identifier | ArrayPattern) ',')* ('...' Identifier?)? ']'
+type ArrayPattern struct {
+	Token    token.Token  // token.LBracket
+	Elements []Expression // Identifier or ArrayPattern. any Pattern in a MatchArm
+	HasRest  bool         // pattern ends with '...' and matches any remaining elements
+	Rest     *Identifier  // optional name bound to the remaining elements
+}
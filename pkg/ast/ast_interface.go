@@ -26,6 +26,11 @@ func (l *LetStatement) TokenLiteral() string {
 	return l.Token.Literal
 }
 
+// TokenLiteral impl for ClassStatement
+func (c *ClassStatement) TokenLiteral() string {
+	return c.Token.Literal
+}
+
 // TokenLiteral impl for Identifier
 func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
@@ -86,11 +91,31 @@ func (w *WhileExpression) TokenLiteral() string {
 	return w.Token.Literal
 }
 
+// TokenLiteral for ForExpression
+func (f *ForExpression) TokenLiteral() string {
+	return f.Token.Literal
+}
+
+// TokenLiteral for MatchExpression
+func (m *MatchExpression) TokenLiteral() string {
+	return m.Token.Literal
+}
+
+// TokenLiteral for RangePattern
+func (r *RangePattern) TokenLiteral() string {
+	return r.Token.Literal
+}
+
 // TokenLiteral for FunctionLiteral
 func (f *FunctionLiteral) TokenLiteral() string {
 	return f.Token.Literal
 }
 
+// TokenLiteral for YieldExpression
+func (y *YieldExpression) TokenLiteral() string {
+	return y.Token.Literal
+}
+
 // TokenLiteral for BlockStatement
 func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
@@ -101,6 +126,16 @@ func (c *CallExpression) TokenLiteral() string {
 	return c.Token.Literal
 }
 
+// TokenLiteral for NamedArgument
+func (n *NamedArgument) TokenLiteral() string {
+	return n.Token.Literal
+}
+
+// TokenLiteral for SpreadExpression
+func (s *SpreadExpression) TokenLiteral() string {
+	return s.Token.Literal
+}
+
 // TokenLiteral for StringLiteral
 func (s *StringLiteral) TokenLiteral() string {
 	return s.Token.Literal
@@ -111,11 +146,21 @@ func (a *ArrayLiteral) TokenLiteral() string {
 	return a.Token.Literal
 }
 
+// TokenLiteral for SliceExpression
+func (s *SliceExpression) TokenLiteral() string {
+	return s.Token.Literal
+}
+
 // TokenLiteral for IndexExpression
 func (i *IndexExpression) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+// TokenLiteral for ArrayPattern
+func (a *ArrayPattern) TokenLiteral() string {
+	return a.Token.Literal
+}
+
 // **---String-implementations---** //
 
 // String for Program
@@ -135,7 +180,11 @@ func (l *LetStatement) String() string {
 
 	b.WriteString(l.TokenLiteral())
 	b.WriteByte(' ')
-	b.WriteString(l.Name.String())
+	if l.Pattern != nil {
+		b.WriteString(l.Pattern.String())
+	} else {
+		b.WriteString(l.Name.String())
+	}
 	b.WriteString(" = ")
 
 	if l.Value != nil {
@@ -147,6 +196,25 @@ func (l *LetStatement) String() string {
 	return b.String()
 }
 
+// String for ClassStatement
+func (c *ClassStatement) String() string {
+	var b bytes.Buffer
+
+	b.WriteString("class ")
+	b.WriteString(c.Name.String())
+	if c.Parent != nil {
+		b.WriteString(" < ")
+		b.WriteString(c.Parent.String())
+	}
+	b.WriteString(" { ")
+	for _, f := range c.Fields {
+		b.WriteString(f.String())
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
 // String for PrefixExpression
 func (p *PrefixExpression) String() string {
 	var b bytes.Buffer
@@ -187,6 +255,29 @@ func (i *IndexExpression) String() string {
 	return b.String()
 }
 
+// String for SliceExpression
+func (s *SliceExpression) String() string {
+	var b bytes.Buffer
+
+	b.WriteByte('(')
+	b.WriteString(s.Left.String())
+	b.WriteByte('[')
+	if s.Start != nil {
+		b.WriteString(s.Start.String())
+	}
+	b.WriteByte(':')
+	if s.End != nil {
+		b.WriteString(s.End.String())
+	}
+	if s.Step != nil {
+		b.WriteByte(':')
+		b.WriteString(s.Step.String())
+	}
+	b.WriteString("])")
+
+	return b.String()
+}
+
 // String for ReturnStatement
 func (r *ReturnStatement) String() string {
 	var b bytes.Buffer
@@ -232,24 +323,95 @@ func (w *WhileExpression) String() string {
 	return b.String()
 }
 
-// String got FunctionLiteral
-func (f *FunctionLiteral) String() string {
+// String for ForExpression
+func (f *ForExpression) String() string {
+	var b bytes.Buffer
+
+	b.WriteString("for ")
+	b.WriteString(f.Var.String())
+	b.WriteString(" in ")
+	b.WriteString(f.Iter.String())
+	b.WriteByte(' ')
+	b.WriteString(f.Do.String())
+
+	return b.String()
+}
+
+// String for MatchExpression
+func (m *MatchExpression) String() string {
+	var b bytes.Buffer
+
+	b.WriteString("match ")
+	b.WriteString(m.Value.String())
+	b.WriteString(" { ")
+	for _, a := range m.Arms {
+		b.WriteString(a.String())
+		b.WriteString(", ")
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// String for MatchArm
+func (m *MatchArm) String() string {
 	var b bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Params {
-		params = append(params, p.String())
+	b.WriteString(m.Pattern.String())
+	if m.Guard != nil {
+		b.WriteString(" if ")
+		b.WriteString(m.Guard.String())
 	}
+	b.WriteString(" => ")
+	b.WriteString(m.Body.String())
 
-	b.WriteString(f.TokenLiteral())
-	b.WriteString(strings.Join(params, ", "))
-	b.WriteString(f.TokenLiteral())
+	return b.String()
+}
+
+// String for RangePattern
+func (r *RangePattern) String() string {
+	return r.Low.String() + ".." + r.High.String()
+}
+
+// String got FunctionLiteral
+func (f *FunctionLiteral) String() string {
+	var b bytes.Buffer
+
+	b.WriteString(Signature(f.Params, f.Defaults, f.Rest))
 	b.WriteByte(' ')
 	b.WriteString(f.Body.String())
 
 	return b.String()
 }
 
+// String for YieldExpression
+func (y *YieldExpression) String() string {
+	return y.TokenLiteral() + " " + y.Value.String()
+}
+
+// Signature formats function parameters as they are written. e.g. |a, b = 10, ...rest|
+func Signature(params []Expression, defaults []Expression, rest *Identifier) string {
+	var b bytes.Buffer
+
+	ps := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			ps = append(ps, p.String()+" = "+defaults[i].String())
+		} else {
+			ps = append(ps, p.String())
+		}
+	}
+	if rest != nil {
+		ps = append(ps, "..."+rest.String())
+	}
+
+	b.WriteByte('|')
+	b.WriteString(strings.Join(ps, ", "))
+	b.WriteByte('|')
+
+	return b.String()
+}
+
 // String for BlockStatement
 func (bs *BlockStatement) String() string {
 	var b bytes.Buffer
@@ -282,6 +444,16 @@ func (c *CallExpression) String() string {
 	return b.String()
 }
 
+// String for NamedArgument
+func (n *NamedArgument) String() string {
+	return n.Name.String() + ": " + n.Value.String()
+}
+
+// String for SpreadExpression
+func (s *SpreadExpression) String() string {
+	return "..." + s.Value.String()
+}
+
 // String for ArrayLiteral
 func (a *ArrayLiteral) String() string {
 	var b bytes.Buffer
@@ -298,6 +470,29 @@ func (a *ArrayLiteral) String() string {
 	return b.String()
 }
 
+// String for ArrayPattern
+func (a *ArrayPattern) String() string {
+	var b bytes.Buffer
+
+	elems := []string{}
+	for _, e := range a.Elements {
+		elems = append(elems, e.String())
+	}
+	if a.HasRest {
+		rest := "..."
+		if a.Rest != nil {
+			rest += a.Rest.String()
+		}
+		elems = append(elems, rest)
+	}
+
+	b.WriteByte('[')
+	b.WriteString(strings.Join(elems, ", "))
+	b.WriteByte(']')
+
+	return b.String()
+}
+
 // String for Identifier
 func (i *Identifier) String() string {
 	return i.Value
@@ -358,6 +553,7 @@ func (l *LetStatement) statementNode()        {}
 func (e *ExpressionStatement) statementNode() {}
 func (r *ReturnStatement) statementNode()     {}
 func (bs *BlockStatement) statementNode()     {}
+func (c *ClassStatement) statementNode()      {}
 
 // Expression is the basis for a expression in the ast
 type Expression interface {
@@ -376,9 +572,17 @@ func (i *InfixExpression) expressionNode()  {}
 func (b *BooleanLiteral) expressionNode()   {}
 func (f *IfExpression) expressionNode()     {}
 func (w *WhileExpression) expressionNode()  {}
+func (f *ForExpression) expressionNode()    {}
+func (m *MatchExpression) expressionNode()  {}
+func (r *RangePattern) expressionNode()     {}
+func (y *YieldExpression) expressionNode()  {}
 func (f *FunctionLiteral) expressionNode()  {}
 func (c *CallExpression) expressionNode()   {}
 func (s *StringLiteral) expressionNode()    {}
+func (s *SpreadExpression) expressionNode() {}
+func (n *NamedArgument) expressionNode()    {}
 func (a *ArrayLiteral) expressionNode()     {}
 func (i *IndexExpression) expressionNode()  {}
+func (s *SliceExpression) expressionNode()  {}
 func (n *NilLiteral) expressionNode()       {}
+func (a *ArrayPattern) expressionNode()     {}
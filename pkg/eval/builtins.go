@@ -7,42 +7,156 @@ import (
 	"io/ioutil"
 	"jacob/dusk/pkg/object"
 	"jacob/dusk/pkg/token"
-	"math/rand"
+	"math/big"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
 // OutStream out
 var OutStream io.Writer = os.Stdout
 
-var builtins = map[string]*object.Builtin{
-	"len":     &object.Builtin{Fn: length},
-	"first":   &object.Builtin{Fn: first},
-	"last":    &object.Builtin{Fn: last},
-	"rest":    &object.Builtin{Fn: rest},
-	"lead":    &object.Builtin{Fn: lead},
-	"push":    &object.Builtin{Fn: push},
-	"pop":     &object.Builtin{Fn: pop},
-	"alloc":   &object.Builtin{Fn: alloc},
-	"set":     &object.Builtin{Fn: set},
-	"join":    &object.Builtin{Fn: join},
-	"split":   &object.Builtin{Fn: split},
-	"println": &object.Builtin{Fn: println},
-	"print":   &object.Builtin{Fn: print},
-	"readln":  &object.Builtin{Fn: readln},
-	"read":    &object.Builtin{Fn: read},
-	"readc":   &object.Builtin{Fn: readc},
-	"readall": &object.Builtin{Fn: readall},
-	"atoi":    &object.Builtin{Fn: atoi},
-	"itoa":    &object.Builtin{Fn: itoa},
-	"in":      &object.Builtin{Fn: in},
-	"out":     &object.Builtin{Fn: out},
-	"rand":    &object.Builtin{Fn: random},
-	"sleep":   &object.Builtin{Fn: sleep},
-}
-
-func sleep(args ...object.Object) object.Object {
+// InStream in. the read builtins share one buffered reader over it so no input is lost between calls
+var InStream io.Reader = os.Stdin
+
+var (
+	inReader *bufio.Reader
+	inSource io.Reader
+	inMu     sync.Mutex // held while reading so spawned functions don't read at the same time
+)
+
+// input is the shared reader over InStream. it's made again if InStream is changed.
+// inMu must be held
+func input() *bufio.Reader {
+	if inReader == nil || inSource != InStream {
+		inSource = InStream
+		inReader = bufio.NewReader(InStream)
+	}
+	return inReader
+}
+
+// builtins are set in init since some of them call back into Eval, which looks up builtins
+var builtins map[string]*object.Builtin
+
+// namespaces are modules of builtins accessed with '.' e.g math.sqrt
+var namespaces map[string]*object.Module
+
+func init() {
+	builtins = map[string]*object.Builtin{
+		"len":     &object.Builtin{Fn: length},
+		"first":   &object.Builtin{Fn: first},
+		"last":    &object.Builtin{Fn: last},
+		"rest":    &object.Builtin{Fn: rest},
+		"lead":    &object.Builtin{Fn: lead},
+		"push":    &object.Builtin{Fn: push},
+		"pop":     &object.Builtin{Fn: pop},
+		"insert":  &object.Builtin{Fn: insert},
+		"remove":  &object.Builtin{Fn: remove},
+		"clear":   &object.Builtin{Fn: clearArray},
+		"copy":    &object.Builtin{Fn: copyArray},
+		"alloc":   &object.Builtin{Fn: alloc},
+		"set":     &object.Builtin{Fn: set},
+		"join":    &object.Builtin{Fn: join},
+		"split":   &object.Builtin{Fn: split},
+		"println": &object.Builtin{Fn: println},
+		"print":   &object.Builtin{Fn: print},
+		"readln":  &object.Builtin{Fn: readln},
+		"read":    &object.Builtin{Fn: read},
+		"readc":   &object.Builtin{Fn: readc},
+		"readall": &object.Builtin{Fn: readall},
+		"eof":     &object.Builtin{Fn: eof},
+		"atoi":    &object.Builtin{Fn: atoi},
+		"itoa":    &object.Builtin{Fn: itoa},
+		"in":      fsFn(in),
+		"out":     fsFn(out),
+		"rand":    &object.Builtin{Fn: random},
+		"seed":    &object.Builtin{Fn: seed},
+		"sleep":   &object.Builtin{Fn: sleep},
+
+		"bigint":   &object.Builtin{Fn: bigint},
+		"rational": &object.Builtin{Fn: rational},
+		"typeof":   &object.Builtin{Fn: typeOf},
+		"is":       &object.Builtin{Fn: is},
+		"freeze":   &object.Builtin{Fn: freeze},
+
+		"map":       &object.Builtin{Fn: mapFn},
+		"filter":    &object.Builtin{Fn: filter},
+		"reduce":    &object.Builtin{Fn: reduce},
+		"each":      &object.Builtin{Fn: each},
+		"any":       &object.Builtin{Fn: anyFn},
+		"all":       &object.Builtin{Fn: allFn},
+		"find":      &object.Builtin{Fn: find},
+		"index_of":  &object.Builtin{Fn: indexOf},
+		"contains":  &object.Builtin{Fn: contains},
+		"zip":       &object.Builtin{Fn: zip},
+		"enumerate": &object.Builtin{Fn: enumerate},
+		"flatten":   &object.Builtin{Fn: flatten},
+		"sort":      &object.Builtin{Fn: sortFn},
+		"sort_by":   &object.Builtin{Fn: sortBy},
+		"uniq":      &object.Builtin{Fn: uniq},
+		"group_by":  &object.Builtin{Fn: groupBy},
+		"sum":       &object.Builtin{Fn: sum},
+		"min":       &object.Builtin{Fn: minFn},
+		"max":       &object.Builtin{Fn: maxFn},
+		"reverse":   &object.Builtin{Fn: reverse},
+		"hashmap":   &object.Builtin{Fn: hashmap},
+		"keys":      &object.Builtin{Fn: keys},
+		"values":    &object.Builtin{Fn: values},
+
+		"upper":       &object.Builtin{Fn: upper},
+		"lower":       &object.Builtin{Fn: lower},
+		"trim":        &object.Builtin{Fn: trimmer("trim", strings.TrimFunc, strings.Trim)},
+		"trim_left":   &object.Builtin{Fn: trimmer("trim_left", strings.TrimLeftFunc, strings.TrimLeft)},
+		"trim_right":  &object.Builtin{Fn: trimmer("trim_right", strings.TrimRightFunc, strings.TrimRight)},
+		"starts_with": &object.Builtin{Fn: startsWith},
+		"ends_with":   &object.Builtin{Fn: endsWith},
+		"index":       &object.Builtin{Fn: stringIndex},
+		"replace":     &object.Builtin{Fn: replace},
+		"repeat":      &object.Builtin{Fn: repeat},
+		"pad_left":    &object.Builtin{Fn: padder("pad_left", true)},
+		"pad_right":   &object.Builtin{Fn: padder("pad_right", false)},
+		"lines":       &object.Builtin{Fn: lines},
+		"chars":       &object.Builtin{Fn: chars},
+		"format":      &object.Builtin{Fn: format},
+
+		"int":       &object.Builtin{Fn: toInt},
+		"float":     &object.Builtin{Fn: toFloat},
+		"str":       &object.Builtin{Fn: toStr},
+		"bool":      &object.Builtin{Fn: toBool},
+		"parse_int": &object.Builtin{Fn: parseInt},
+		"re":        &object.Builtin{Fn: re},
+
+		"json_parse":     &object.Builtin{Fn: jsonParse},
+		"json_stringify": &object.Builtin{Fn: jsonStringify},
+
+		"rand_int":   &object.Builtin{Fn: randInt},
+		"rand_float": &object.Builtin{Fn: randFloat},
+		"choice":     &object.Builtin{Fn: choice},
+		"shuffle":    &object.Builtin{Fn: shuffle},
+		"sample":     &object.Builtin{Fn: sample},
+
+		"chan":   &object.Builtin{Fn: makeChannel},
+		"spawn":  &object.Builtin{Fn: spawn},
+		"select": &object.Builtin{Fn: selectChannel},
+
+		"iter":    &object.Builtin{Fn: iter},
+		"take":    &object.Builtin{Fn: take},
+		"skip":    &object.Builtin{Fn: skip},
+		"chain":   &object.Builtin{Fn: chain},
+		"collect": &object.Builtin{Fn: collect},
+		"range":   &object.Builtin{Fn: rangeIter},
+	}
+
+	namespaces = map[string]*object.Module{
+		"math": mathModule(),
+		"fs":   fsModule(),
+		"os":   osModule(),
+		"time": timeModule(),
+	}
+}
+
+func sleep(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -51,32 +165,15 @@ func sleep(args ...object.Object) object.Object {
 	case *object.Integer:
 		time.Sleep(time.Duration(t.Value) * time.Millisecond)
 		return ConstNil
+	case *object.Duration:
+		time.Sleep(t.Value)
+		return ConstNil
 	default:
 		return newError(token.Position{}, "wrong arg types")
 	}
 }
 
-func random(args ...object.Object) object.Object {
-	if len(args) == 0 {
-		return &object.Float{Value: rand.Float64()}
-	} else if len(args) == 2 {
-		switch min := args[0].(type) {
-		case *object.Integer:
-			switch max := args[1].(type) {
-			case *object.Integer:
-				return &object.Integer{Value: rand.Int63n(max.Value-min.Value) + min.Value}
-			default:
-				return newError(token.Position{}, "wrong arg types")
-			}
-		default:
-			return newError(token.Position{}, "wrong arg types")
-		}
-	} else {
-		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 2'", len(args))
-	}
-}
-
-func length(args ...object.Object) object.Object {
+func length(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -86,12 +183,25 @@ func length(args ...object.Object) object.Object {
 		return &object.Integer{Value: int64(len(arg.Value))}
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Map:
+		return &object.Integer{Value: int64(arg.Len())}
 	default:
+		if fn, ok := specialMethod(arg, "__len"); ok {
+			return call.Call(fn)
+		}
+		// iterators are counted by using up their values
+		if isIterator(arg) {
+			items, err := elements(call, "len", arg)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(len(items))}
+		}
 		return newError(token.Position{}, "argument to 'len' not supported, got '%s'", args[0].Type())
 	}
 }
 
-func first(args ...object.Object) object.Object {
+func first(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -112,7 +222,7 @@ func first(args ...object.Object) object.Object {
 	}
 }
 
-func last(args ...object.Object) object.Object {
+func last(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -133,7 +243,7 @@ func last(args ...object.Object) object.Object {
 	}
 }
 
-func rest(args ...object.Object) object.Object {
+func rest(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -160,7 +270,7 @@ func rest(args ...object.Object) object.Object {
 	}
 }
 
-func lead(args ...object.Object) object.Object {
+func lead(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -187,7 +297,8 @@ func lead(args ...object.Object) object.Object {
 	}
 }
 
-func push(args ...object.Object) object.Object {
+// push appends to an array in place and returns it. strings are values so a new string is returned
+func push(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 2 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
 	}
@@ -200,17 +311,17 @@ func push(args ...object.Object) object.Object {
 		}
 		return newError(token.Position{}, "cannot push '%s' to string", args[1].Type())
 	case *object.Array:
-		l := len(arg.Elements)
-		newElems := make([]object.Object, l+1, l+1)
-		copy(newElems, arg.Elements)
-		newElems[l] = args[1]
-		return &object.Array{Elements: newElems}
+		if arg.Frozen {
+			return newError(token.Position{}, "cannot push to frozen array")
+		}
+		arg.Elements = append(arg.Elements, args[1])
+		return arg
 	default:
 		return newError(token.Position{}, "argument to 'push' not supported, got '%s'", args[0].Type())
 	}
 }
 
-func pop(args ...object.Object) object.Object {
+func pop(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -225,6 +336,9 @@ func pop(args ...object.Object) object.Object {
 		}
 		return ConstNil
 	case *object.Array:
+		if arg.Frozen {
+			return newError(token.Position{}, "cannot pop from frozen array")
+		}
 		l := len(arg.Elements)
 		if l > 0 {
 			p := arg.Elements[l-1]
@@ -237,7 +351,133 @@ func pop(args ...object.Object) object.Object {
 	}
 }
 
-func alloc(args ...object.Object) object.Object {
+// insert puts the value before index i of the array in place and returns the array.
+// negative indices count back from the end so -1 appends
+func insert(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '3'", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "argument to 'insert' not supported, got '%s'", args[0].Type())
+	}
+	if arr.Frozen {
+		return newError(token.Position{}, "cannot insert into frozen array")
+	}
+
+	index, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError(token.Position{}, "second argument to 'insert' must be type 'int'. got '%s'", args[1].Type())
+	}
+
+	l := int64(len(arr.Elements))
+	i := index.Value
+	if i < 0 {
+		i = l + i + 1
+	}
+	if i < 0 || i > l {
+		return newError(token.Position{}, "index '%d' out of bounds of array. Max '%d'", index.Value, l)
+	}
+
+	arr.Elements = append(arr.Elements, nil)
+	copy(arr.Elements[i+1:], arr.Elements[i:])
+	arr.Elements[i] = args[2]
+	return arr
+}
+
+// remove takes the element at index i out of the array in place and returns it.
+// for maps it takes out the key and returns its value
+func remove(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+
+	if m, ok := args[0].(*object.Map); ok {
+		return removeKey(m, args[1])
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "argument to 'remove' not supported, got '%s'", args[0].Type())
+	}
+	if arr.Frozen {
+		return newError(token.Position{}, "cannot remove from frozen array")
+	}
+
+	index, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError(token.Position{}, "second argument to 'remove' must be type 'int'. got '%s'", args[1].Type())
+	}
+
+	max := int64(len(arr.Elements) - 1)
+	i := index.Value
+	if i < 0 {
+		i = max + i + 1
+	}
+	if i < 0 || i > max {
+		return newError(token.Position{}, "index '%d' out of bounds of array. Max '%d'", index.Value, max)
+	}
+
+	removed := arr.Elements[i]
+	copy(arr.Elements[i:], arr.Elements[i+1:])
+	arr.Elements[max] = nil
+	arr.Elements = arr.Elements[:max]
+	return removed
+}
+
+// removeKey deletes the key from the map and returns its value. nil if it was not in the map
+func removeKey(m *object.Map, k object.Object) object.Object {
+	if m.Frozen {
+		return newError(token.Position{}, "cannot remove from frozen map")
+	}
+
+	key, ok := k.(object.Hashable)
+	if !ok {
+		return newError(token.Position{}, "cannot use type '%s' as a map key", k.Type())
+	}
+
+	if val, ok := m.Delete(key); ok {
+		return val
+	}
+	return ConstNil
+}
+
+// clearArray removes every element from the array in place and returns it
+func clearArray(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "argument to 'clear' not supported, got '%s'", args[0].Type())
+	}
+	if arr.Frozen {
+		return newError(token.Position{}, "cannot clear frozen array")
+	}
+
+	arr.Elements = []object.Object{}
+	return arr
+}
+
+// copyArray makes a new array with the same elements. the copy is never frozen
+func copyArray(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "argument to 'copy' not supported, got '%s'", args[0].Type())
+	}
+
+	elems := make([]object.Object, len(arr.Elements))
+	copy(elems, arr.Elements)
+	return &object.Array{Elements: elems}
+}
+
+func alloc(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 2 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
 	}
@@ -257,14 +497,20 @@ func alloc(args ...object.Object) object.Object {
 	}
 }
 
-func set(args ...object.Object) object.Object {
+func set(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 3 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '3'", len(args))
 	}
 
 	switch arg := args[0].(type) {
 	case *object.Array:
+		if arg.Frozen {
+			return newError(token.Position{}, "cannot set index of frozen array")
+		}
 		if i, ok := args[1].(*object.Integer); ok {
+			if i.Value < 0 || i.Value >= int64(len(arg.Elements)) {
+				return newError(token.Position{}, "index '%d' out of bounds of array. Max '%d'", i.Value, len(arg.Elements)-1)
+			}
 			arg.Elements[i.Value] = args[2]
 			return ConstNil
 		}
@@ -274,7 +520,7 @@ func set(args ...object.Object) object.Object {
 	}
 }
 
-func join(args ...object.Object) object.Object {
+func join(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 2 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
 	}
@@ -294,7 +540,7 @@ func join(args ...object.Object) object.Object {
 	}
 }
 
-func split(args ...object.Object) object.Object {
+func split(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 2 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
 	}
@@ -317,67 +563,126 @@ func split(args ...object.Object) object.Object {
 	}
 }
 
-func println(args ...object.Object) object.Object {
+func println(call object.Caller, args ...object.Object) object.Object {
 	for _, arg := range args {
-		fmt.Fprintln(OutStream, arg)
+		str := stringify(call, arg)
+		if isError(str) {
+			return str
+		}
+		fmt.Fprintln(OutStream, str)
 	}
 	return ConstNil
 }
 
-func print(args ...object.Object) object.Object {
+func print(call object.Caller, args ...object.Object) object.Object {
 	for _, arg := range args {
-		fmt.Fprintln(OutStream, arg)
+		str := stringify(call, arg)
+		if isError(str) {
+			return str
+		}
+		fmt.Fprintln(OutStream, str)
 	}
 	return ConstNil
 }
 
-func readln(args ...object.Object) object.Object {
+// readln reads the next line without the new line. nil at the end of the input
+func readln(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 0 {
 		return newError(token.Position{}, "readln does not take any arguments. given '%d'", len(args))
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
+	inMu.Lock()
+	defer inMu.Unlock()
 
-	return &object.String{Value: scanner.Text()}
+	line, err := input().ReadString('\n')
+	if err != nil && err != io.EOF {
+		return newError(token.Position{}, "%s", err)
+	}
+	if err == io.EOF && line == "" {
+		return ConstNil
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return &object.String{Value: strings.TrimSuffix(line, "\r")}
 }
 
-func read(args ...object.Object) object.Object {
+// read reads the next word separated by white space. nil at the end of the input
+func read(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 0 {
-		return newError(token.Position{}, "readln does not take any arguments. given '%d'", len(args))
+		return newError(token.Position{}, "read does not take any arguments. given '%d'", len(args))
 	}
 
+	inMu.Lock()
+	defer inMu.Unlock()
+
 	s := ""
-	fmt.Scan(&s)
+	if _, err := fmt.Fscan(input(), &s); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ConstNil
+		}
+		return newError(token.Position{}, "%s", err)
+	}
 
 	return &object.String{Value: s}
 }
 
-func readc(args ...object.Object) object.Object {
+// readc reads the next character. nil at the end of the input
+func readc(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 0 {
-		return newError(token.Position{}, "readln does not take any arguments. given '%d'", len(args))
+		return newError(token.Position{}, "readc does not take any arguments. given '%d'", len(args))
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	c, e := reader.ReadByte()
-	if e != nil {
+	inMu.Lock()
+	defer inMu.Unlock()
+
+	c, _, err := input().ReadRune()
+	if err == io.EOF {
 		return ConstNil
 	}
+	if err != nil {
+		return newError(token.Position{}, "%s", err)
+	}
 
 	return &object.String{Value: string(c)}
 }
 
-func readall(args ...object.Object) object.Object {
+// readall reads the rest of the input. nil if it's already at the end
+func readall(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 0 {
-		return newError(token.Position{}, "readln does not take any arguments. given '%d'", len(args))
+		return newError(token.Position{}, "readall does not take any arguments. given '%d'", len(args))
 	}
 
-	s, _ := ioutil.ReadAll(os.Stdin)
+	inMu.Lock()
+	defer inMu.Unlock()
+
+	if eofReached() {
+		return ConstNil
+	}
+	s, err := ioutil.ReadAll(input())
+	if err != nil {
+		return newError(token.Position{}, "%s", err)
+	}
 
 	return &object.String{Value: string(s)}
 }
 
-func in(args ...object.Object) object.Object {
+// eof is true when there is no more input to read
+func eof(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "eof does not take any arguments. given '%d'", len(args))
+	}
+
+	inMu.Lock()
+	defer inMu.Unlock()
+	return boolToBoolean(eofReached())
+}
+
+func eofReached() bool {
+	_, err := input().Peek(1)
+	return err != nil
+}
+
+func in(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "in takes one arguments. given '%d'", len(args))
 	}
@@ -386,41 +691,48 @@ func in(args ...object.Object) object.Object {
 	case *object.String:
 		f, err := os.Open(arg.Value)
 		if err != nil {
-			return newError(token.Position{}, err.Error())
+			return newError(token.Position{}, "%s", err)
 		}
 		defer f.Close()
-		s, _ := ioutil.ReadAll(f)
+		s, err := ioutil.ReadAll(f)
+		if err != nil {
+			return newError(token.Position{}, "%s", err)
+		}
 		return &object.String{Value: string(s)}
 	default:
 		return newError(token.Position{}, "argument to 'in' not supported, got '%s'", args[0].Type())
 	}
 }
 
-func out(args ...object.Object) object.Object {
+func out(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 2 {
-		return newError(token.Position{}, "out takes one arguments. given '%d'", len(args))
+		return newError(token.Position{}, "out takes two arguments. given '%d'", len(args))
 	}
 
-	switch arg := args[0].(type) {
-	case *object.String:
-		f, err := os.Create(arg.Value)
-		if err != nil {
-			return newError(token.Position{}, err.Error())
-		}
-		defer f.Close()
-		switch str := args[1].(type) {
-		case *object.String:
-			f.WriteString(str.Value)
-			return nil
-		default:
-			return newError(token.Position{}, "argument to 'out' not supported, got '%s'", args[0].Type())
-		}
-	default:
+	path, ok := args[0].(*object.String)
+	if !ok {
 		return newError(token.Position{}, "argument to 'out' not supported, got '%s'", args[0].Type())
 	}
+	str, ok := args[1].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "second argument to 'out' not supported, got '%s'", args[1].Type())
+	}
+
+	f, err := os.Create(path.Value)
+	if err != nil {
+		return newError(token.Position{}, "%s", err)
+	}
+	if _, err := f.WriteString(str.Value); err != nil {
+		f.Close()
+		return newError(token.Position{}, "%s", err)
+	}
+	if err := f.Close(); err != nil {
+		return newError(token.Position{}, "%s", err)
+	}
+	return ConstNil
 }
 
-func atoi(args ...object.Object) object.Object {
+func atoi(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -437,7 +749,7 @@ func atoi(args ...object.Object) object.Object {
 	}
 }
 
-func itoa(args ...object.Object) object.Object {
+func itoa(call object.Caller, args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
 	}
@@ -452,3 +764,121 @@ func itoa(args ...object.Object) object.Object {
 		return newError(token.Position{}, "argument to 'atoi' not supported, got '%s'", args[0].Type())
 	}
 }
+
+func bigint(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return &object.BigInt{Value: big.NewInt(arg.Value)}
+	case *object.BigInt:
+		return arg
+	case *object.String:
+		if v, ok := new(big.Int).SetString(arg.Value, 10); ok {
+			return &object.BigInt{Value: v}
+		}
+		return newError(token.Position{}, "could not parse '%s' as bigint", arg.Value)
+	default:
+		return newError(token.Position{}, "argument to 'bigint' not supported, got '%s'", args[0].Type())
+	}
+}
+
+// rational takes a single number or string such as '1/3' or '0.25'
+// or a numerator and denominator
+func rational(call object.Caller, args ...object.Object) object.Object {
+	switch len(args) {
+	case 1:
+		switch arg := args[0].(type) {
+		case *object.Integer, *object.BigInt, *object.Rational:
+			return promote(arg, object.RationalType)
+		case *object.Float:
+			if v := new(big.Rat).SetFloat64(arg.Value); v != nil {
+				return &object.Rational{Value: v}
+			}
+			return newError(token.Position{}, "cannot convert '%s' to rational", arg)
+		case *object.String:
+			if v, ok := new(big.Rat).SetString(arg.Value); ok {
+				return &object.Rational{Value: v}
+			}
+			return newError(token.Position{}, "could not parse '%s' as rational", arg.Value)
+		default:
+			return newError(token.Position{}, "argument to 'rational' not supported, got '%s'", args[0].Type())
+		}
+	case 2:
+		if !isInteger(args[0]) || !isInteger(args[1]) {
+			return newError(token.Position{}, "arguments to 'rational' must be 'int' or 'bigint', got '%s' and '%s'", args[0].Type(), args[1].Type())
+		}
+		num := promote(args[0], object.BigIntType).(*object.BigInt).Value
+		denom := promote(args[1], object.BigIntType).(*object.BigInt).Value
+		if denom.Sign() == 0 {
+			return newError(token.Position{}, "rational denominator cannot be 0")
+		}
+		return &object.Rational{Value: new(big.Rat).SetFrac(num, denom)}
+	default:
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+}
+
+// typeOf is the name of the type of the arg. instances give the name of their class
+func typeOf(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	if instance, ok := args[0].(*object.Instance); ok {
+		return &object.String{Value: instance.Class.Name}
+	}
+	return &object.String{Value: args[0].Type().String()}
+}
+
+// is checks if the first arg is an instance of the class or a subclass of it
+// a type name string can be given instead of a class
+func is(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+
+	switch t := args[1].(type) {
+	case *object.Class:
+		instance, ok := args[0].(*object.Instance)
+		return boolToBoolean(ok && instance.Class.Is(t))
+	case *object.String:
+		if instance, ok := args[0].(*object.Instance); ok {
+			for c := instance.Class; c != nil; c = c.Parent {
+				if c.Name == t.Value {
+					return ConstTrue
+				}
+			}
+		}
+		return boolToBoolean(args[0].Type().String() == t.Value)
+	default:
+		return newError(token.Position{}, "second argument to 'is' must be a class or type name. got '%s'", args[1].Type())
+	}
+}
+
+// freeze makes an array or map and all the arrays and maps inside it immutable. returns the arg
+func freeze(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Array:
+		if !arg.Frozen {
+			arg.Frozen = true
+			for _, e := range arg.Elements {
+				freeze(call, e)
+			}
+		}
+	case *object.Map:
+		if !arg.Frozen {
+			arg.Frozen = true
+			for _, p := range arg.Pairs() {
+				freeze(call, p.Value)
+			}
+		}
+	}
+	return args[0]
+}
@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+func evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
+	if env.IsLocalConst(node.Name.Value) {
+		return newError(node.Name.Token.Pos, "cannot redeclare constant '%s'", node.Name.Value)
+	}
+
+	class := &object.Class{Name: node.Name.Value, Fields: node.Fields, Env: env}
+
+	if node.Parent != nil {
+		parent, ok := env.Get(node.Parent.Value)
+		if !ok {
+			return newError(node.Parent.Token.Pos, "identifier not found: %s", node.Parent.Value)
+		}
+
+		p, ok := parent.(*object.Class)
+		if !ok {
+			return newError(node.Parent.Token.Pos, "cannot inherit from type '%s'. Must be class", parent.Type())
+		}
+		class.Parent = p
+	}
+
+	env.Set(class.Name, class)
+	return nil
+}
+
+// instantiate creates a new instance of class and calls its init method with the args
+func instantiate(t token.Token, class *object.Class, args []object.Object, named []namedArg, env *object.Environment, stop <-chan struct{}) object.Object {
+	instance := &object.Instance{Class: class, Fields: object.NewEnvironment()}
+
+	if err := initFields(class, instance, stop); err != nil {
+		return err
+	}
+
+	init, ok := instance.Fields.Get("init")
+	if !ok {
+		if len(args) > 0 || len(named) > 0 {
+			return newError(t.Pos, "class %s has no init method and takes no arguments", class.Name)
+		}
+		return instance
+	}
+
+	result := doFunctionNamed(t, init, args, named, env, stop)
+	if isError(result) {
+		return result
+	}
+
+	return instance
+}
+
+// initFields evaluates the fields of class and its parents into the instance.
+// parent fields are set first so the child can override them.
+// each class evaluates its fields with self bound to the instance and
+// super bound to the methods of its parent
+func initFields(class *object.Class, instance *object.Instance, stop <-chan struct{}) object.Object {
+	env := object.NewChildEnvironment(class.Env)
+	env.Set("self", instance)
+
+	if class.Parent != nil {
+		if err := initFields(class.Parent, instance, stop); err != nil {
+			return err
+		}
+
+		super := &object.Instance{Class: class.Parent, Fields: object.NewEnvironment()}
+		for _, name := range class.Parent.FieldNames() {
+			if v, ok := instance.Fields.Get(name); ok && v.Type() == object.FunctionType {
+				super.Fields.Set(name, v)
+			}
+		}
+		env.Set("super", super)
+	}
+
+	for _, f := range class.Fields {
+		if instance.Fields.IsLocalConst(f.Name.Value) {
+			return newError(f.Name.Token.Pos, "cannot redeclare constant '%s'", f.Name.Value)
+		}
+
+		val := Eval(f.Value, env, stop)
+		if isError(val) {
+			return val
+		}
+		if f.Token.Type == token.Const {
+			instance.Fields.SetConst(f.Name.Value, val)
+		} else {
+			instance.Fields.Set(f.Name.Value, val)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,755 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"sort"
+	"strings"
+)
+
+// elements collects the items of an iterable argument for the builtin called name
+func elements(call object.Caller, name string, o object.Object) ([]object.Object, object.Object) {
+	if !isIterable(o) {
+		return nil, newError(token.Position{}, "argument to '%s' not supported, got '%s'", name, o.Type())
+	}
+
+	items := []object.Object{}
+	// errors come from the code making the values and a stopped program gives back nil
+	err := iterate(token.Position{}, o, call.Env(), call.Stop(), func(item object.Object) object.Object {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// isCallable is true for objects that can be called like a function
+func isCallable(o object.Object) bool {
+	switch o.Type() {
+	case object.FunctionType, object.BuiltinType, object.ClassType:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectionArgs checks the args of a builtin that takes an iterable and a function
+func collectionArgs(call object.Caller, name string, args []object.Object) ([]object.Object, object.Object, object.Object) {
+	if len(args) != 2 {
+		return nil, nil, newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, newError(token.Position{}, "second argument to '%s' must be a function. got '%s'", name, args[1].Type())
+	}
+
+	items, err := elements(call, name, args[0])
+	return items, args[1], err
+}
+
+// equals compares a and b with ==
+func equals(call object.Caller, a, b object.Object) object.Object {
+	return evalInfixExpr(token.Token{Type: token.Equal, Literal: "=="}, a, b, call.Env(), call.Stop())
+}
+
+// compare orders a and b. negative if a comes first, 0 if equal, positive if b comes first.
+// numbers, strings, arrays of comparable values and instances with __lt can be compared
+func compare(call object.Caller, a, b object.Object) (int, object.Object) {
+	switch {
+	case numericRank(a) > 0 && numericRank(b) > 0:
+		less := evalInfixExpr(token.Token{Type: token.Less, Literal: "<"}, a, b, call.Env(), call.Stop())
+		if less == ConstTrue {
+			return -1, nil
+		}
+		greater := evalInfixExpr(token.Token{Type: token.Greater, Literal: ">"}, a, b, call.Env(), call.Stop())
+		if greater == ConstTrue {
+			return 1, nil
+		}
+		return 0, nil
+	case a.Type() == object.StringType && b.Type() == object.StringType:
+		return strings.Compare(a.(*object.String).Value, b.(*object.String).Value), nil
+	case a.Type() == object.ArrayType && b.Type() == object.ArrayType:
+		left, right := a.(*object.Array).Elements, b.(*object.Array).Elements
+		for i := 0; i < len(left) && i < len(right); i++ {
+			if c, err := compare(call, left[i], right[i]); c != 0 || err != nil {
+				return c, err
+			}
+		}
+		return len(left) - len(right), nil
+	}
+
+	// instances that overload '<' or '>'
+	less, ok, err := overloadedLess(call, a, b)
+	if ok || err != nil {
+		if less || err != nil {
+			return -1, err
+		}
+		greater, _, err := overloadedLess(call, b, a)
+		if greater {
+			return 1, err
+		}
+		return 0, err
+	}
+
+	return 0, newError(token.Position{}, "cannot compare type '%s' and '%s'", a.Type(), b.Type())
+}
+
+// overloadedLess checks a < b with the __lt method of a or the __gt method of b
+// ok is false if neither is defined
+func overloadedLess(call object.Caller, a, b object.Object) (less, ok bool, err object.Object) {
+	fn, ok := specialMethod(a, "__lt")
+	other := b
+	if !ok {
+		if fn, ok = specialMethod(b, "__gt"); !ok {
+			return false, false, nil
+		}
+		other = a
+	}
+
+	result := call.Call(fn, other)
+	if isError(result) {
+		return false, true, result
+	}
+	return isTruthy(result), true, nil
+}
+
+// sortObjects sorts items in place with less. the sort is stable
+func sortObjects(items []object.Object, less func(a, b object.Object) (bool, object.Object)) object.Object {
+	var err object.Object
+	sort.SliceStable(items, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		l, e := less(items[i], items[j])
+		if e != nil {
+			err = e
+		}
+		return l
+	})
+	return err
+}
+
+func mapFn(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "map", args)
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, len(items))
+	for i, item := range items {
+		val := call.Call(fn, item)
+		if isError(val) {
+			return val
+		}
+		result[i] = val
+	}
+	return &object.Array{Elements: result}
+}
+
+func filter(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "filter", args)
+	if err != nil {
+		return err
+	}
+
+	result := []object.Object{}
+	for _, item := range items {
+		keep := call.Call(fn, item)
+		if isError(keep) {
+			return keep
+		}
+		if isTruthy(keep) {
+			result = append(result, item)
+		}
+	}
+	return &object.Array{Elements: result}
+}
+
+// reduce combines the items with fn(acc, item) starting from init or the first item
+func reduce(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2 or 3'", len(args))
+	}
+
+	items, fn, err := collectionArgs(call, "reduce", args[:2])
+	if err != nil {
+		return err
+	}
+
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if len(items) == 0 {
+			return newError(token.Position{}, "cannot reduce empty %s without an initial value", args[0].Type())
+		}
+		acc, items = items[0], items[1:]
+	}
+
+	for _, item := range items {
+		acc = call.Call(fn, acc, item)
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+func each(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "each", args)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if val := call.Call(fn, item); isError(val) {
+			return val
+		}
+	}
+	return ConstNil
+}
+
+// truthyArgs checks the args of any and all. without a function the items themselves are tested
+func truthyArgs(name string, call object.Caller, args []object.Object) ([]object.Object, func(object.Object) (bool, object.Object), object.Object) {
+	if len(args) == 1 {
+		items, err := elements(call, name, args[0])
+		return items, func(o object.Object) (bool, object.Object) { return isTruthy(o), nil }, err
+	}
+
+	items, fn, err := collectionArgs(call, name, args)
+	test := func(o object.Object) (bool, object.Object) {
+		val := call.Call(fn, o)
+		if isError(val) {
+			return false, val
+		}
+		return isTruthy(val), nil
+	}
+	return items, test, err
+}
+
+func anyFn(call object.Caller, args ...object.Object) object.Object {
+	items, test, err := truthyArgs("any", call, args)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		ok, err := test(item)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return ConstTrue
+		}
+	}
+	return ConstFalse
+}
+
+func allFn(call object.Caller, args ...object.Object) object.Object {
+	items, test, err := truthyArgs("all", call, args)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		ok, err := test(item)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ConstFalse
+		}
+	}
+	return ConstTrue
+}
+
+// find the first item that fn is true for. nil if there is none
+func find(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "find", args)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		found := call.Call(fn, item)
+		if isError(found) {
+			return found
+		}
+		if isTruthy(found) {
+			return item
+		}
+	}
+	return ConstNil
+}
+
+// indexOf finds the index of the first item equal to the value. -1 if there is none
+func indexOf(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+
+	items, err := elements(call, "index_of", args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		eq := equals(call, item, args[1])
+		if isError(eq) {
+			return eq
+		}
+		if eq == ConstTrue {
+			return &object.Integer{Value: int64(i)}
+		}
+	}
+	return &object.Integer{Value: -1}
+}
+
+// contains checks if an array has an item equal to the value,
+// a string has the value as a substring, or a map has the value as a key
+func contains(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return newError(token.Position{}, "second argument to 'contains' must be a string. got '%s'", args[1].Type())
+		}
+		return boolToBoolean(strings.Contains(arg.Value, sub.Value))
+	case *object.Map:
+		key, ok := args[1].(object.Hashable)
+		if !ok {
+			return ConstFalse
+		}
+		_, ok = arg.Get(key)
+		return boolToBoolean(ok)
+	default:
+		if !isIterable(arg) {
+			return newError(token.Position{}, "argument to 'contains' not supported, got '%s'", arg.Type())
+		}
+		index := indexOf(call, args...)
+		if i, ok := index.(*object.Integer); ok {
+			return boolToBoolean(i.Value >= 0)
+		}
+		return index
+	}
+}
+
+// zip makes an array of the items at the same index in each arg. stops at the shortest
+func zip(call object.Caller, args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '0', expected at least '1'")
+	}
+
+	lists := make([][]object.Object, len(args))
+	shortest := -1
+	for i, arg := range args {
+		items, err := elements(call, "zip", arg)
+		if err != nil {
+			return err
+		}
+		lists[i] = items
+		if shortest < 0 || len(items) < shortest {
+			shortest = len(items)
+		}
+	}
+
+	result := make([]object.Object, shortest)
+	for i := range result {
+		tuple := make([]object.Object, len(lists))
+		for j := range lists {
+			tuple[j] = lists[j][i]
+		}
+		result[i] = &object.Array{Elements: tuple}
+	}
+	return &object.Array{Elements: result}
+}
+
+// enumerate pairs each item with its index. [[0, a], [1, b], ...]
+func enumerate(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	items, err := elements(call, "enumerate", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, len(items))
+	for i, item := range items {
+		result[i] = &object.Array{Elements: []object.Object{&object.Integer{Value: int64(i)}, item}}
+	}
+	return &object.Array{Elements: result}
+}
+
+// flatten puts the items of nested arrays into one array. depth limits how many levels are flattened
+func flatten(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "argument to 'flatten' not supported, got '%s'", args[0].Type())
+	}
+
+	depth := int64(-1)
+	if len(args) == 2 {
+		d, ok := args[1].(*object.Integer)
+		if !ok {
+			return newError(token.Position{}, "second argument to 'flatten' must be type 'int'. got '%s'", args[1].Type())
+		}
+		depth = d.Value
+	}
+
+	var flat func(elems []object.Object, depth int64, seen map[*object.Array]bool) ([]object.Object, object.Object)
+	flat = func(elems []object.Object, depth int64, seen map[*object.Array]bool) ([]object.Object, object.Object) {
+		result := []object.Object{}
+		for _, e := range elems {
+			inner, ok := e.(*object.Array)
+			if !ok || depth == 0 {
+				result = append(result, e)
+				continue
+			}
+			if seen[inner] {
+				return nil, newError(token.Position{}, "cannot flatten array that contains itself")
+			}
+
+			seen[inner] = true
+			items, err := flat(inner.Elements, depth-1, seen)
+			if err != nil {
+				return nil, err
+			}
+			delete(seen, inner)
+			result = append(result, items...)
+		}
+		return result, nil
+	}
+
+	result, err := flat(arr.Elements, depth, map[*object.Array]bool{arr: true})
+	if err != nil {
+		return err
+	}
+	return &object.Array{Elements: result}
+}
+
+// sortFn returns a sorted copy of the items. the optional comparator is called with two items
+// and returns true or a negative int if the first should come before the second
+func sortFn(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+
+	items, err := elements(call, "sort", args[0])
+	if err != nil {
+		return err
+	}
+
+	less := func(a, b object.Object) (bool, object.Object) {
+		c, err := compare(call, a, b)
+		return c < 0, err
+	}
+
+	if len(args) == 2 {
+		cmp := args[1]
+		if !isCallable(cmp) {
+			return newError(token.Position{}, "second argument to 'sort' must be a function. got '%s'", cmp.Type())
+		}
+
+		less = func(a, b object.Object) (bool, object.Object) {
+			result := call.Call(cmp, a, b)
+			switch result := result.(type) {
+			case *object.Error:
+				return false, result
+			case *object.Integer:
+				return result.Value < 0, nil
+			default:
+				return isTruthy(result), nil
+			}
+		}
+	}
+
+	if err := sortObjects(items, less); err != nil {
+		return err
+	}
+	return &object.Array{Elements: items}
+}
+
+// sortBy returns a copy of the items sorted by the key fn gives for each item
+func sortBy(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "sort_by", args)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[object.Object]object.Object, len(items))
+	for _, item := range items {
+		if _, ok := keys[item]; ok {
+			continue
+		}
+		key := call.Call(fn, item)
+		if isError(key) {
+			return key
+		}
+		keys[item] = key
+	}
+
+	err = sortObjects(items, func(a, b object.Object) (bool, object.Object) {
+		c, err := compare(call, keys[a], keys[b])
+		return c < 0, err
+	})
+	if err != nil {
+		return err
+	}
+	return &object.Array{Elements: items}
+}
+
+// uniq returns the items without duplicates. the first of equal items is kept
+func uniq(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	items, err := elements(call, "uniq", args[0])
+	if err != nil {
+		return err
+	}
+
+	seen := map[object.HashKey]bool{}
+	result := []object.Object{}
+
+next:
+	for _, item := range items {
+		if h, ok := item.(object.Hashable); ok {
+			if seen[h.HashKey()] {
+				continue
+			}
+			seen[h.HashKey()] = true
+			result = append(result, item)
+			continue
+		}
+
+		for _, r := range result {
+			eq := equals(call, item, r)
+			if isError(eq) {
+				return eq
+			}
+			if eq == ConstTrue {
+				continue next
+			}
+		}
+		result = append(result, item)
+	}
+	return &object.Array{Elements: result}
+}
+
+// groupBy makes a map from each key fn gives to the array of items with that key
+func groupBy(call object.Caller, args ...object.Object) object.Object {
+	items, fn, err := collectionArgs(call, "group_by", args)
+	if err != nil {
+		return err
+	}
+
+	groups := object.NewMap()
+	for _, item := range items {
+		k := call.Call(fn, item)
+		if isError(k) {
+			return k
+		}
+		key, ok := k.(object.Hashable)
+		if !ok {
+			return newError(token.Position{}, "cannot use type '%s' as a map key", k.Type())
+		}
+
+		group, ok := groups.Get(key)
+		if !ok {
+			group = &object.Array{Elements: []object.Object{}}
+			groups.Set(key, group)
+		}
+		group.(*object.Array).Elements = append(group.(*object.Array).Elements, item)
+	}
+	return groups
+}
+
+// sum adds the items together with '+'. 0 if there are none
+func sum(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	items, err := elements(call, "sum", args[0])
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return &object.Integer{Value: 0}
+	}
+
+	total := items[0]
+	for _, item := range items[1:] {
+		total = evalInfixExpr(token.Token{Type: token.Plus, Literal: "+"}, total, item, call.Env(), call.Stop())
+		if isError(total) {
+			return total
+		}
+	}
+	return total
+}
+
+// extreme finds the smallest item, or the largest if sign is 1.
+// takes one iterable or many args. nil if there are no items
+func extreme(name string, sign int, call object.Caller, args []object.Object) object.Object {
+	items := args
+	if len(args) == 1 {
+		var err object.Object
+		if items, err = elements(call, name, args[0]); err != nil {
+			return err
+		}
+	}
+
+	if len(items) == 0 {
+		return ConstNil
+	}
+
+	best := items[0]
+	for _, item := range items[1:] {
+		c, err := compare(call, item, best)
+		if err != nil {
+			return err
+		}
+		if c*sign > 0 {
+			best = item
+		}
+	}
+	return best
+}
+
+func minFn(call object.Caller, args ...object.Object) object.Object {
+	return extreme("min", -1, call, args)
+}
+
+func maxFn(call object.Caller, args ...object.Object) object.Object {
+	return extreme("max", 1, call, args)
+}
+
+// reverse returns the items in the opposite order. strings are reversed by character
+func reverse(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	if str, ok := args[0].(*object.String); ok {
+		runes := []rune(str.Value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return &object.String{Value: string(runes)}
+	}
+
+	items, err := elements(call, "reverse", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, len(items))
+	for i, item := range items {
+		result[len(items)-1-i] = item
+	}
+	return &object.Array{Elements: result}
+}
+
+// hashmap makes a new map. optionally from an iterable of [key, value] pairs
+func hashmap(call object.Caller, args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 1'", len(args))
+	}
+
+	m := object.NewMap()
+	if len(args) == 0 {
+		return m
+	}
+
+	items, err := elements(call, "hashmap", args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		pair, ok := item.(*object.Array)
+		if !ok || len(pair.Elements) != 2 {
+			return newError(token.Position{}, "hashmap needs [key, value] pairs. got '%s'", item)
+		}
+		key, ok := pair.Elements[0].(object.Hashable)
+		if !ok {
+			return newError(token.Position{}, "cannot use type '%s' as a map key", pair.Elements[0].Type())
+		}
+		m.Set(key, pair.Elements[1])
+	}
+	return m
+}
+
+// mapParts lists the keys or values of a map in the order they were added
+func mapParts(name string, args []object.Object, part func(*object.MapPair) object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	m, ok := args[0].(*object.Map)
+	if !ok {
+		return newError(token.Position{}, "argument to '%s' not supported, got '%s'", name, args[0].Type())
+	}
+
+	result := []object.Object{}
+	for _, pair := range m.Pairs() {
+		result = append(result, part(pair))
+	}
+	return &object.Array{Elements: result}
+}
+
+func keys(call object.Caller, args ...object.Object) object.Object {
+	return mapParts("keys", args, func(p *object.MapPair) object.Object { return p.Key })
+}
+
+func values(call object.Caller, args ...object.Object) object.Object {
+	return mapParts("values", args, func(p *object.MapPair) object.Object { return p.Value })
+}
+
+// evalMapAssign sets the value of a key in a map
+func evalMapAssign(node *ast.InfixExpression, target *ast.IndexExpression, m *object.Map, env *object.Environment, stop <-chan struct{}) object.Object {
+	if m.Frozen {
+		return newError(target.Token.Pos, "cannot assign key of frozen map")
+	}
+
+	index := Eval(target.Index, env, stop)
+	if isError(index) {
+		return index
+	}
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError(target.Token.Pos, "cannot use type '%s' as a map key", index.Type())
+	}
+
+	right := Eval(node.Right, env, stop)
+	if isError(right) {
+		return right
+	}
+
+	if node.Operator != token.Assign {
+		current, ok := m.Get(key)
+		if !ok {
+			return newError(target.Token.Pos, "key '%s' not in map", key)
+		}
+		right = evalCompound(node, current, right, env, stop)
+		if isError(right) {
+			return right
+		}
+	}
+
+	m.Set(key, right)
+	return right
+}
@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"reflect"
+	"time"
+)
+
+// newChannel makes a Channel with its methods as members
+func newChannel(c chan object.Object) *object.Channel {
+	ch := &object.Channel{Value: c, Env: object.NewEnvironment()}
+	ch.Env.SetConst("send", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+		}
+		return send(c, args[0], call.Stop())
+	}})
+	ch.Env.SetConst("recv", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+		}
+		return recv(c, call.Stop())
+	}})
+	ch.Env.SetConst("close", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+		}
+		return closeChannel(c)
+	}})
+	ch.Env.SetConst("len", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+		}
+		return &object.Integer{Value: int64(len(c))}
+	}})
+	return ch
+}
+
+// send sends v on c. go panics on a closed channel so it's turned into an error.
+// it gives up if the interpreter is stopped while it waits
+func send(c chan object.Object, v object.Object, stop <-chan struct{}) (result object.Object) {
+	defer func() {
+		if recover() != nil {
+			result = newError(token.Position{}, "cannot send on a closed channel")
+		}
+	}()
+	select {
+	case c <- v:
+	case <-stop:
+	}
+	return ConstNil
+}
+
+// recv receives from c. nil if it's closed or the interpreter is stopped while it waits
+func recv(c chan object.Object, stop <-chan struct{}) object.Object {
+	select {
+	case v, ok := <-c:
+		if ok {
+			return v
+		}
+	case <-stop:
+	}
+	return ConstNil
+}
+
+func closeChannel(c chan object.Object) (result object.Object) {
+	defer func() {
+		if recover() != nil {
+			result = newError(token.Position{}, "cannot close a closed channel")
+		}
+	}()
+	close(c)
+	return ConstNil
+}
+
+// makeChannel makes a channel that holds up to n values before send waits. the default is 0
+func makeChannel(call object.Caller, args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 1'", len(args))
+	}
+	n, err := intArg("chan", args, 0, 0)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return newError(token.Position{}, "channel size cannot be negative. got '%d'", n)
+	}
+	return newChannel(make(chan object.Object, n))
+}
+
+// spawn calls a function with args on its own goroutine.
+// it returns a channel that gets the result, or the error, when the function is done
+func spawn(call object.Caller, args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected at least '1'", len(args))
+	}
+	if !isCallable(args[0]) {
+		return newError(token.Position{}, "first argument to 'spawn' must be a function. got '%s'", args[0].Type())
+	}
+
+	done := make(chan object.Object, 1)
+	go func() {
+		result := call.Call(args[0], args[1:]...)
+		if result == nil {
+			result = ConstNil
+		}
+		done <- result
+		close(done)
+	}()
+	return newChannel(done)
+}
+
+// selectChannel waits until one of the cases can go ahead and returns [index, value].
+// a channel case receives and a [channel, value] case sends. a closed channel receives nil.
+// the optional timeout is a duration or ms. nil is returned if it runs out first and 0 doesn't wait.
+// nil is also returned if the interpreter is stopped while it waits
+func selectChannel(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(token.Position{}, "first argument to 'select' must be an array. got '%s'", args[0].Type())
+	}
+
+	cases := make([]reflect.SelectCase, 0, len(arr.Elements)+1)
+	for i, c := range arr.Elements {
+		switch c := c.(type) {
+		case *object.Channel:
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.Value)})
+			continue
+		case *object.Array:
+			if len(c.Elements) == 2 {
+				if ch, ok := c.Elements[0].(*object.Channel); ok {
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch.Value), Send: reflect.ValueOf(&c.Elements[1]).Elem()})
+					continue
+				}
+			}
+		}
+		return newError(token.Position{}, "select case %d must be a channel or a [channel, value] pair. got '%s'", i, c.Type())
+	}
+
+	if len(args) == 2 {
+		var wait time.Duration
+		switch t := args[1].(type) {
+		case *object.Integer:
+			wait = time.Duration(t.Value) * time.Millisecond
+		case *object.Duration:
+			wait = t.Value
+		default:
+			return newError(token.Position{}, "second argument to 'select' must be a duration or int. got '%s'", t.Type())
+		}
+		if wait <= 0 {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+		} else {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)})
+		}
+	} else if len(cases) == 0 {
+		return newError(token.Position{}, "select needs a case or a timeout")
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(call.Stop())})
+
+	return doSelect(cases, len(arr.Elements))
+}
+
+// doSelect runs the select. a chosen index of n or more is the timeout or the stop
+func doSelect(cases []reflect.SelectCase, n int) (result object.Object) {
+	defer func() {
+		if recover() != nil {
+			result = newError(token.Position{}, "cannot send on a closed channel")
+		}
+	}()
+
+	chosen, v, ok := reflect.Select(cases)
+	if chosen >= n {
+		return ConstNil
+	}
+
+	var value object.Object = ConstNil
+	if ok && cases[chosen].Dir == reflect.SelectRecv {
+		value = v.Interface().(object.Object)
+	}
+	return &object.Array{Elements: []object.Object{&object.Integer{Value: int64(chosen)}, value}}
+}
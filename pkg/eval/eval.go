@@ -6,6 +6,7 @@ import (
 	"jacob/dusk/pkg/object"
 	"jacob/dusk/pkg/token"
 	"math"
+	"math/big"
 )
 
 var (
@@ -33,13 +34,17 @@ func bottomEnv(id *ast.AccessIdentifier, env *object.Environment) (*object.Envir
 
 	for i, v := range id.Values {
 		if i < len(id.Values)-1 {
-			if val, ok := currentEnv.Get(v); ok {
-				if val.Type() != object.FunctionType {
-					return nil, v, newError(id.Token.Pos, "cannot use '.' on type '%s'. Must be function", val.Type())
+			val, ok := currentEnv.Get(v)
+			if module, found := namespaces[v]; !ok && found && i == 0 {
+				val, ok = module, true
+			}
+			if ok {
+				scope, ok := val.(object.Scope)
+				if !ok {
+					return nil, v, newError(id.Token.Pos, "cannot use '.' on type '%s'. Must be function, instance or module", val.Type())
 				}
 
-				val := val.(*object.Function)
-				currentEnv = val.Env
+				currentEnv = scope.Members()
 			} else {
 				return nil, v, newError(id.Token.Pos, "identifier not found in context: %s", v)
 			}
@@ -62,17 +67,40 @@ func Eval(node ast.Node, env *object.Environment, stop <-chan struct{}) object.O
 	case *ast.Program:
 		return evalProgram(node, env, stop)
 	case *ast.LetStatement:
+		if node.Pattern != nil {
+			if err := checkRedeclare(node.Pattern, env); err != nil {
+				return err
+			}
+		} else if env.IsLocalConst(node.Name.Value) {
+			return newError(node.Name.Token.Pos, "cannot redeclare constant '%s'", node.Name.Value)
+		}
+
 		val := Eval(node.Value, env, stop)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.Pattern != nil {
+			if err := bindPattern(node.Pattern, val, env); err != nil {
+				return err
+			}
+			if node.Token.Type == token.Const {
+				constPattern(node.Pattern, env)
+			}
+			return nil
+		}
+		if node.Token.Type == token.Const {
+			env.SetConst(node.Name.Value, val)
+		} else {
+			env.Set(node.Name.Value, val)
+		}
 	case *ast.ReturnStatement:
 		val := Eval(node.Value, env, stop)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.ClassStatement:
+		return evalClassStatement(node, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env, stop)
 	case *ast.ExpressionStatement:
@@ -86,7 +114,7 @@ func Eval(node ast.Node, env *object.Environment, stop <-chan struct{}) object.O
 		}
 		return evalPrefixExpr(node.Token, right)
 	case *ast.InfixExpression:
-		if node.Operator == token.Assign {
+		if isAssignment(node.Operator) {
 			return evalAssign(node, env, stop)
 		}
 
@@ -98,7 +126,7 @@ func Eval(node ast.Node, env *object.Environment, stop <-chan struct{}) object.O
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpr(node.Token, left, right)
+		return evalInfixExpr(node.Token, left, right, env, stop)
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env, stop)
 		if isError(left) {
@@ -109,23 +137,29 @@ func Eval(node ast.Node, env *object.Environment, stop <-chan struct{}) object.O
 		if isError(index) {
 			return index
 		}
-		return evalIndexExpr(node.Token, left, index)
+		return evalIndexExpr(node.Token, left, index, env, stop)
+	case *ast.SliceExpression:
+		return evalSliceExpr(node, env, stop)
 	case *ast.IfExpression:
 		return evalIfExpr(node, env, stop)
 	case *ast.WhileExpression:
 		return evalWhileExpr(node, env, stop)
+	case *ast.ForExpression:
+		return evalForExpr(node, env, stop)
+	case *ast.MatchExpression:
+		return evalMatchExpr(node, env, stop)
 	case *ast.CallExpression:
 		function := Eval(node.Func, env, stop)
 		if isError(function) {
 			return function
 		}
 
-		args, err := evalExpressions(node.Args, env, stop)
+		args, named, err := evalCallArgs(node.Args, env, stop)
 		if err != nil {
 			return err
 		}
 
-		return doFunction(node.Token, function, args, stop)
+		return doFunctionNamed(node.Token, function, args, named, env, stop)
 
 		// literals
 	case *ast.IntegerLiteral:
@@ -137,15 +171,19 @@ func Eval(node ast.Node, env *object.Environment, stop <-chan struct{}) object.O
 	case *ast.NilLiteral:
 		return ConstNil
 	case *ast.FunctionLiteral:
-		return &object.Function{Params: node.Params, Body: node.Body, Env: env}
+		return &object.Function{Params: node.Params, Defaults: node.Defaults, Rest: node.Rest, Body: node.Body, Env: env, Generator: node.Generator}
+	case *ast.YieldExpression:
+		return evalYieldExpr(node, env, stop)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.ArrayLiteral:
 		elems, err := evalExpressions(node.Elements, env, stop)
-		if len(elems) == 1 && err != nil {
+		if err != nil {
 			return err
 		}
 		return &object.Array{Elements: elems}
+	case *ast.SpreadExpression:
+		return newError(node.Token.Pos, "'...' can only be used in call arguments or array literals")
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.AccessIdentifier:
@@ -195,6 +233,20 @@ func evalExpressions(expressions []ast.Expression, env *object.Environment, stop
 	var evaluated []object.Object
 
 	for _, e := range expressions {
+		// ...array expands to each of its elements
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			evaled := Eval(spread.Value, env, stop)
+			if isError(evaled) {
+				return []object.Object{}, evaled
+			}
+			array, ok := evaled.(*object.Array)
+			if !ok {
+				return []object.Object{}, newError(spread.Token.Pos, "cannot spread type '%s'. Must be array", evaled.Type())
+			}
+			evaluated = append(evaluated, array.Elements...)
+			continue
+		}
+
 		evaled := Eval(e, env, stop)
 		if isError(evaled) {
 			return []object.Object{}, evaled
@@ -205,6 +257,38 @@ func evalExpressions(expressions []ast.Expression, env *object.Environment, stop
 	return evaluated, nil
 }
 
+// namedArg is an argument passed by parameter name. e.g. f(age: 3)
+type namedArg struct {
+	name  string
+	value object.Object
+}
+
+// evalCallArgs evaluates the arguments of a call once each, left to right in source order
+// and splits them into positional and named. the parser ensures the named arguments come last
+func evalCallArgs(expressions []ast.Expression, env *object.Environment, stop <-chan struct{}) ([]object.Object, []namedArg, object.Object) {
+	var args []object.Object
+	var named []namedArg
+
+	for _, e := range expressions {
+		if arg, ok := e.(*ast.NamedArgument); ok {
+			val := Eval(arg.Value, env, stop)
+			if isError(val) {
+				return nil, nil, val
+			}
+			named = append(named, namedArg{arg.Name.Value, val})
+			continue
+		}
+
+		evaled, err := evalExpressions([]ast.Expression{e}, env, stop)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, evaled...)
+	}
+
+	return args, named, nil
+}
+
 func evalIfExpr(node *ast.IfExpression, env *object.Environment, stop <-chan struct{}) object.Object {
 	cond := Eval(node.Cond, env, stop)
 	if isError(cond) {
@@ -236,9 +320,14 @@ func evalWhileExpr(node *ast.WhileExpression, env *object.Environment, stop <-ch
 
 		select {
 		case <-stop:
-			break
+			return nil
 		default:
-			Eval(node.Do, env, stop)
+		}
+
+		// returns and errors stop the loop. a closed generator unwinds this way
+		result := Eval(node.Do, env, stop)
+		if result != nil && (result.Type() == object.ReturnType || result.Type() == object.ErrorType) {
+			return result
 		}
 
 		if node.Then != nil {
@@ -250,6 +339,32 @@ func evalWhileExpr(node *ast.WhileExpression, env *object.Environment, stop <-ch
 	}
 }
 
+func evalForExpr(node *ast.ForExpression, env *object.Environment, stop <-chan struct{}) object.Object {
+	iterable := Eval(node.Iter, env, stop)
+	if isError(iterable) {
+		return iterable
+	}
+
+	result := iterate(node.Token.Pos, iterable, env, stop, func(item object.Object) object.Object {
+		// each item gets a fresh scope so closures capture the current item
+		loopEnv := object.NewChildEnvironment(env)
+		if err := bindPattern(node.Var, item, loopEnv); err != nil {
+			return err
+		}
+
+		result := Eval(node.Do, loopEnv, stop)
+		if result != nil && (result.Type() == object.ReturnType || result.Type() == object.ErrorType) {
+			return result
+		}
+		return nil
+	})
+	if result != nil {
+		return result
+	}
+
+	return ConstNil
+}
+
 // isTruthy - everything is true execpt for false and nil
 func isTruthy(o object.Object) bool {
 	switch o {
@@ -266,6 +381,14 @@ func isTruthy(o object.Object) bool {
 			if o.(*object.Float).Value == 0.0 {
 				return false
 			}
+		case object.BigIntType:
+			if o.(*object.BigInt).Value.Sign() == 0 {
+				return false
+			}
+		case object.RationalType:
+			if o.(*object.Rational).Value.Sign() == 0 {
+				return false
+			}
 		}
 
 		return true
@@ -285,42 +408,42 @@ func evalPrefixExpr(op token.Token, right object.Object) object.Object {
 		return evalBangOperatorExpr(right)
 	case token.Minus:
 		return evalMinusPrefixOperatorExpr(op.Pos, right)
+	case token.Tilde:
+		return evalTildePrefixOperatorExpr(op.Pos, right)
 	default:
 		return newError(op.Pos, "unknown operator '%s' for type '%s'", op, right.Type())
 	}
 }
 
-func evalInfixExpr(op token.Token, left, right object.Object) object.Object {
+func evalInfixExpr(op token.Token, left, right object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+
+	// instances can overload the operator with a special method
+	if result, ok := evalOverloadedInfixExpr(op, left, right, env, stop); ok {
+		return result
+	}
 
 	// catch early type errors
 	if !left.CanApply(op.Type, right.Type()) && (op.Type != token.Equal && op.Type != token.NotEqual) {
 		return newError(op.Pos, "cannot apply operator '%s' for type '%s' and '%s'", op, left.Type(), right.Type())
 	}
 
-	// test and convert int to float if needed
-	if left.Type() == object.IntType && right.Type() == object.IntType {
-		// both ints. easy
-		return evalIntegerInfixExpr(op, left, right)
-	} else if left.Type() == object.FloatType && right.Type() == object.FloatType {
-		// both floats. easy
-		return evalFloatInfixExpr(op, left, right)
-	}
-
-	// one of them must be a int and the other a float
-	if left.Type() == object.IntType || right.Type() == object.IntType {
-		if left.Type() == object.FloatType {
-			// left is float, right is int.
-			// premote right to float
-			val := right.(*object.Integer).Value
-			right = &object.Float{Value: float64(val)}
-			return evalFloatInfixExpr(op, left, right)
-
-		} else if right.Type() == object.FloatType {
-			// right is float, left is int
-			// premote left to float
-			val := left.(*object.Integer).Value
-			left = &object.Float{Value: float64(val)}
+	// two numbers. premote the lower ranked one to the type of the other
+	// int -> bigint -> rational -> float
+	if leftRank, rightRank := numericRank(left), numericRank(right); leftRank > 0 && rightRank > 0 {
+		if leftRank < rightRank {
+			left = promote(left, right.Type())
+		} else if rightRank < leftRank {
+			right = promote(right, left.Type())
+		}
 
+		switch left.Type() {
+		case object.IntType:
+			return evalIntegerInfixExpr(op, left, right)
+		case object.BigIntType:
+			return evalBigIntInfixExpr(op, left, right)
+		case object.RationalType:
+			return evalRationalInfixExpr(op, left, right)
+		default:
 			return evalFloatInfixExpr(op, left, right)
 		}
 	}
@@ -332,7 +455,17 @@ func evalInfixExpr(op token.Token, left, right object.Object) object.Object {
 
 	// two arrays
 	if left.Type() == object.ArrayType && right.Type() == object.ArrayType {
-		return evalArrayInfixExpr(op, left, right)
+		return evalArrayInfixExpr(op, left, right, env, stop)
+	}
+
+	// two maps
+	if left.Type() == object.MapType && right.Type() == object.MapType {
+		return evalMapInfixExpr(op, left, right, env, stop)
+	}
+
+	// times and durations. numbers can scale a duration from the left too
+	if left.Type() == object.TimeType || left.Type() == object.DurationType || right.Type() == object.DurationType {
+		return evalTimeInfixExpr(op, left, right)
 	}
 
 	// compare actual runtime object
@@ -346,8 +479,14 @@ func evalInfixExpr(op token.Token, left, right object.Object) object.Object {
 	return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op, left.Type(), right.Type())
 }
 
-func evalIndexExpr(op token.Token, left, index object.Object) object.Object {
+func evalIndexExpr(op token.Token, left, index object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+	if fn, ok := specialMethod(left, "__index"); ok {
+		return doFunction(op, fn, []object.Object{index}, env, stop)
+	}
+
 	switch {
+	case left.Type() == object.MapType:
+		return evalMapIndexExpr(op.Pos, left, index)
 	case left.Type() == object.ArrayType && index.Type() == object.IntType:
 		return evalArrayIndexExpr(op.Pos, left, index)
 	case left.Type() == object.StringType && index.Type() == object.IntType:
@@ -357,6 +496,19 @@ func evalIndexExpr(op token.Token, left, index object.Object) object.Object {
 	}
 }
 
+// evalMapIndexExpr gets the value of a key. nil if the key is not in the map
+func evalMapIndexExpr(pos token.Position, m, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError(pos, "cannot use type '%s' as a map key", index.Type())
+	}
+
+	if val, ok := m.(*object.Map).Get(key); ok {
+		return val
+	}
+	return ConstNil
+}
+
 func evalArrayIndexExpr(pos token.Position, arr, index object.Object) object.Object {
 	array := arr.(*object.Array)
 	i := index.(*object.Integer).Value
@@ -405,35 +557,61 @@ func evalStringInfixExpr(op token.Token, left, right object.Object) object.Objec
 	}
 }
 
-func evalArrayInfixExpr(op token.Token, left, right object.Object) object.Object {
+func evalArrayInfixExpr(op token.Token, left, right object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
 	leftVals := left.(*object.Array).Elements
 	rightVals := right.(*object.Array).Elements
 
 	switch op.Type {
 	case token.Plus:
-		return &object.Array{Elements: append(leftVals, rightVals...)}
-	case token.Equal:
-		if len(leftVals) != len(rightVals) {
-			return ConstFalse
+		// always a new array. appending to leftVals could write into its spare capacity
+		elems := make([]object.Object, 0, len(leftVals)+len(rightVals))
+		elems = append(elems, leftVals...)
+		return &object.Array{Elements: append(elems, rightVals...)}
+	case token.Equal, token.NotEqual:
+		equal := token.Token{Type: token.Equal, Literal: "==", Pos: op.Pos}
+		same := len(leftVals) == len(rightVals)
+
+		for i := 0; same && i < len(leftVals); i++ {
+			eq := evalInfixExpr(equal, leftVals[i], rightVals[i], env, stop)
+			if isError(eq) {
+				return eq
+			}
+			same = eq == ConstTrue
 		}
+		return boolToBoolean(same == (op.Type == token.Equal))
+	default:
+		return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op.Type, left.Type(), right.Type())
+	}
+}
+
+// evalMapInfixExpr compares two maps. maps are equal if they have equal values for the same keys
+func evalMapInfixExpr(op token.Token, left, right object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+	leftMap := left.(*object.Map)
+	rightMap := right.(*object.Map)
 
-		for i := range leftVals {
-			if evalInfixExpr(op, leftVals[i], rightVals[i]) == ConstFalse {
-				return ConstFalse
+	switch op.Type {
+	case token.Equal, token.NotEqual:
+		equal := token.Token{Type: token.Equal, Literal: "==", Pos: op.Pos}
+		same := leftMap.Len() == rightMap.Len()
+
+		for _, pair := range leftMap.Pairs() {
+			if !same {
+				break
 			}
-		}
-		return ConstTrue
-	case token.NotEqual:
-		if len(leftVals) != len(rightVals) {
-			return ConstTrue
-		}
 
-		for i := range leftVals {
-			if evalInfixExpr(op, leftVals[i], rightVals[i]) == ConstFalse {
-				return ConstTrue
+			val, ok := rightMap.Get(pair.Key.(object.Hashable))
+			if !ok {
+				same = false
+				break
 			}
+
+			eq := evalInfixExpr(equal, pair.Value, val, env, stop)
+			if isError(eq) {
+				return eq
+			}
+			same = eq == ConstTrue
 		}
-		return ConstFalse
+		return boolToBoolean(same == (op.Type == token.Equal))
 	default:
 		return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op.Type, left.Type(), right.Type())
 	}
@@ -443,25 +621,76 @@ func evalIntegerInfixExpr(op token.Token, left, right object.Object) object.Obje
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	// overflowing results are recalculated as bigints
+	overflow := func() object.Object {
+		return evalBigIntInfixExpr(op, promote(left, object.BigIntType), promote(right, object.BigIntType))
+	}
+
 	switch op.Type {
 	case token.Plus:
-		return &object.Integer{Value: leftVal + rightVal}
+		v := leftVal + rightVal
+		if (v^leftVal)&(v^rightVal) < 0 {
+			return overflow()
+		}
+		return &object.Integer{Value: v}
 	case token.Minus:
-		return &object.Integer{Value: leftVal - rightVal}
+		v := leftVal - rightVal
+		if (leftVal^rightVal)&(leftVal^v) < 0 {
+			return overflow()
+		}
+		return &object.Integer{Value: v}
 	case token.Times:
-		return &object.Integer{Value: leftVal * rightVal}
+		v := leftVal * rightVal
+		if leftVal != 0 && (v/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64)) {
+			return overflow()
+		}
+		return &object.Integer{Value: v}
 	case token.Divide:
 		if rightVal == 0 {
 			return newError(op.Pos, "cannot divide %d by 0", leftVal)
 		}
-		return &object.Integer{Value: leftVal / rightVal}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return overflow()
+		}
+		return &object.Integer{Value: floorDiv(leftVal, rightVal)}
 	case token.Exp:
-		return &object.Integer{Value: int64(math.Pow(float64(leftVal), float64(rightVal)))}
+		// negative powers are exact fractions
+		if rightVal < 0 {
+			return evalRationalInfixExpr(op, promote(left, object.RationalType), promote(right, object.RationalType))
+		}
+		v, ok := powInt(leftVal, rightVal)
+		if !ok {
+			return overflow()
+		}
+		return &object.Integer{Value: v}
 	case token.Mod:
 		if rightVal == 0 {
 			return newError(op.Pos, "cannot modulo %d by 0", leftVal)
 		}
-		return &object.Integer{Value: leftVal % rightVal}
+		if rightVal == -1 {
+			return &object.Integer{Value: 0}
+		}
+		return &object.Integer{Value: leftVal - floorDiv(leftVal, rightVal)*rightVal}
+	case token.BitAnd:
+		return &object.Integer{Value: leftVal & rightVal}
+	case token.Bar:
+		return &object.Integer{Value: leftVal | rightVal}
+	case token.Tilde:
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case token.ShiftLeft:
+		if rightVal < 0 {
+			return newError(op.Pos, "cannot shift %d by negative count %d", leftVal, rightVal)
+		}
+		v := leftVal << uint64(rightVal)
+		if rightVal >= 64 || v>>uint64(rightVal) != leftVal {
+			return overflow()
+		}
+		return &object.Integer{Value: v}
+	case token.ShiftRight:
+		if rightVal < 0 {
+			return newError(op.Pos, "cannot shift %d by negative count %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: leftVal >> uint64(rightVal)}
 	case token.Less:
 		return boolToBoolean(leftVal < rightVal)
 	case token.Greater:
@@ -475,6 +704,16 @@ func evalIntegerInfixExpr(op token.Token, left, right object.Object) object.Obje
 	}
 }
 
+// floorDiv divides rounding towards negative infinity
+// so that a == floorDiv(a, b)*b + a%b always holds with a%b taking the sign of b
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
 func evalFloatInfixExpr(op token.Token, left, right object.Object) object.Object {
 	leftVal := left.(*object.Float).Value
 	rightVal := right.(*object.Float).Value
@@ -488,14 +727,14 @@ func evalFloatInfixExpr(op token.Token, left, right object.Object) object.Object
 		return &object.Float{Value: leftVal * rightVal}
 	case token.Divide:
 		if rightVal == 0 {
-			return newError(op.Pos, "cannot divide %d by 0", leftVal)
+			return newError(op.Pos, "cannot divide %f by 0", leftVal)
 		}
 		return &object.Float{Value: leftVal / rightVal}
 	case token.Exp:
 		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case token.Mod:
 		if rightVal == 0 {
-			return newError(op.Pos, "cannot modulo %d by 0", leftVal)
+			return newError(op.Pos, "cannot modulo %f by 0", leftVal)
 		}
 		return &object.Float{Value: math.Mod(leftVal, rightVal)}
 	case token.Less:
@@ -528,15 +767,37 @@ func evalMinusPrefixOperatorExpr(pos token.Position, right object.Object) object
 	switch right.Type() {
 	case object.IntType:
 		v := right.(*object.Integer).Value
+		if v == math.MinInt64 {
+			return &object.BigInt{Value: new(big.Int).Neg(big.NewInt(v))}
+		}
 		return &object.Integer{Value: -v}
 	case object.FloatType:
 		v := right.(*object.Float).Value
 		return &object.Float{Value: -v}
+	case object.BigIntType:
+		v := right.(*object.BigInt).Value
+		return &object.BigInt{Value: new(big.Int).Neg(v)}
+	case object.RationalType:
+		v := right.(*object.Rational).Value
+		return &object.Rational{Value: new(big.Rat).Neg(v)}
 	default:
 		return newError(pos, "unknown operator '-' for type '%s'", right.Type())
 	}
 }
 
+func evalTildePrefixOperatorExpr(pos token.Position, right object.Object) object.Object {
+	switch right.Type() {
+	case object.IntType:
+		v := right.(*object.Integer).Value
+		return &object.Integer{Value: ^v}
+	case object.BigIntType:
+		v := right.(*object.BigInt).Value
+		return &object.BigInt{Value: new(big.Int).Not(v)}
+	default:
+		return newError(pos, "unknown operator '~' for type '%s'", right.Type())
+	}
+}
+
 func evalIdentifier(id *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(id.Value); ok {
 		return val
@@ -546,6 +807,10 @@ func evalIdentifier(id *ast.Identifier, env *object.Environment) object.Object {
 		return builtin
 	}
 
+	if module, ok := namespaces[id.Value]; ok {
+		return module
+	}
+
 	return newError(id.Token.Pos, "identifier not found: %s", id.Value)
 }
 
@@ -567,7 +832,34 @@ func evalAccessIdentifier(id *ast.AccessIdentifier, env *object.Environment) obj
 	return newError(id.Token.Pos, "identifier not found in context: %s", last)
 }
 
-// special case = assign operator
+// compoundOperators maps each compound assignment to the operator it applies
+var compoundOperators = map[token.Type]token.Type{
+	token.Inc:       token.Plus,
+	token.Dec:       token.Minus,
+	token.MulAssign: token.Times,
+	token.DivAssign: token.Divide,
+	token.ModAssign: token.Mod,
+	token.ExpAssign: token.Exp,
+}
+
+func isAssignment(t token.Type) bool {
+	_, ok := compoundOperators[t]
+	return ok || t == token.Assign
+}
+
+// evalCompound applies the operator of a compound assignment such as += to the
+// current value of the target. plain = assignments return right unchanged
+func evalCompound(node *ast.InfixExpression, current, right object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+	op, ok := compoundOperators[node.Operator]
+	if !ok {
+		return right
+	}
+
+	return evalInfixExpr(token.Token{Type: op, Literal: op.String(), Pos: node.Token.Pos}, current, right, env, stop)
+}
+
+// special case = assign operator and compound assignments such as +=
+// the target is only evaluated once
 func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan struct{}) object.Object {
 
 	var bottom *object.Environment
@@ -590,10 +882,19 @@ func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan
 		if isError(array) {
 			return array
 		}
+		if fn, ok := specialMethod(array, "__setindex"); ok {
+			return evalSetIndex(node, l, array, fn, env, stop)
+		}
+		if m, ok := array.(*object.Map); ok {
+			return evalMapAssign(node, l, m, env, stop)
+		}
 		if array.Type() != object.ArrayType {
 			return newError(l.Token.Pos, "index operator assign not supported on type '%s'", array.Type())
 		}
 		a := array.(*object.Array)
+		if a.Frozen {
+			return newError(l.Token.Pos, "cannot assign index of frozen array")
+		}
 
 		index := Eval(l.Index, env, stop)
 		if isError(index) {
@@ -618,13 +919,20 @@ func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan
 			return right
 		}
 
+		right = evalCompound(node, a.Elements[i], right, env, stop)
+		if isError(right) {
+			return right
+		}
+
 		if array == right {
 			return newError(l.Token.Pos, "cannot assign index of array to self")
 		}
 
-		array.(*object.Array).Elements[index.(*object.Integer).Value] = right
+		a.Elements[i] = right
 		return right
 
+	case *ast.SliceExpression:
+		return evalSliceAssign(node, l, env, stop)
 	default:
 		return newError(node.Token.Pos, "cannot bind a literal to a value")
 	}
@@ -634,6 +942,9 @@ func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan
 		if isError(val) {
 			return val
 		}
+		if bottom.IsConst(id) {
+			return newError(node.Token.Pos, "cannot assign to constant '%s'", id)
+		}
 
 		// eval rhs
 		right := Eval(node.Right, env, stop)
@@ -641,8 +952,14 @@ func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan
 			return right
 		}
 
-		// must be same type
-		if val.Type() == right.Type() || (val.Type() == object.NilType || right.Type() == object.NilType) {
+		right = evalCompound(node, val, right, env, stop)
+		if isError(right) {
+			return right
+		}
+
+		// must be same type. ints and bigints are the same kind
+		// since int arithmetic premotes to a bigint when it overflows
+		if val.Type() == right.Type() || (val.Type() == object.NilType || right.Type() == object.NilType) || (isInteger(val) && isInteger(right)) {
 			v, ok := bottom.Assign(id, right)
 			if ok {
 				return v
@@ -655,15 +972,22 @@ func evalAssign(node *ast.InfixExpression, env *object.Environment, stop <-chan
 	return newError(node.Token.Pos, "cannot assign value to variable '%s' that does not exist", id)
 }
 
-func doFunction(t token.Token, f object.Object, args []object.Object, stop <-chan struct{}) object.Object {
+func doFunction(t token.Token, f object.Object, args []object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+	return doFunctionNamed(t, f, args, nil, env, stop)
+}
+
+// doFunctionNamed calls f with args. env is where it's called from, which builtins can reach
+func doFunctionNamed(t token.Token, f object.Object, args []object.Object, named []namedArg, env *object.Environment, stop <-chan struct{}) object.Object {
 
 	switch function := f.(type) {
 	case *object.Function:
-		if len(function.Params) != len(args) {
-			return newError(t.Pos, "invalid number of arguments for function. Expected %d got %d", len(function.Params), len(args))
+		childEnv, err := adoptFunctionEnv(t, function, args, named, stop)
+		if err != nil {
+			return err
+		}
+		if function.Generator {
+			return newGenerator(function, childEnv, stop)
 		}
-
-		childEnv := adoptFunctionEnv(function, args)
 		evaluated := Eval(function.Body, childEnv, stop)
 
 		if val, ok := evaluated.(*object.ReturnValue); ok {
@@ -672,18 +996,197 @@ func doFunction(t token.Token, f object.Object, args []object.Object, stop <-cha
 
 		return evaluated
 	case *object.Builtin:
-		return function.Fn(args...)
+		if len(named) > 0 {
+			return newError(t.Pos, "builtin functions do not take named arguments. got '%s'", named[0].name)
+		}
+		result := function.Fn(&caller{t: t, env: env, stop: stop}, args...)
+
+		// builtins don't know where they were called from
+		if err, ok := result.(*object.Error); ok && err.Pos == (token.Position{}) {
+			err.Pos = t.Pos
+		}
+		return result
+	case *object.Class:
+		return instantiate(t, function, args, named, env, stop)
 	default:
 		return newError(t.Pos, "type '%s' not a function", f.Type())
 	}
 }
 
-func adoptFunctionEnv(f *object.Function, args []object.Object) *object.Environment {
+// caller is the Caller builtins get. it calls functions as if from where the builtin was called
+type caller struct {
+	t    token.Token
+	env  *object.Environment
+	stop <-chan struct{}
+}
+
+// Call for caller
+func (c *caller) Call(fn object.Object, args ...object.Object) object.Object {
+	return doFunction(c.t, fn, args, c.env, c.stop)
+}
+
+// Env for caller
+func (c *caller) Env() *object.Environment {
+	return c.env
+}
+
+// Stop for caller
+func (c *caller) Stop() <-chan struct{} {
+	return c.stop
+}
+
+// adoptFunctionEnv binds the args to the params of f in a new Environment
+// named args bind to the param with the same name after the positional args.
+// missing args take their default value, evaluated in the new Environment
+// so they can refer to earlier params. extra args are collected by the rest param
+func adoptFunctionEnv(t token.Token, f *object.Function, args []object.Object, named []namedArg, stop <-chan struct{}) (*object.Environment, object.Object) {
+	required := 0
+	for _, d := range f.Defaults {
+		if d == nil {
+			required++
+		}
+	}
+
+	if (len(named) == 0 && len(args) < required) || (f.Rest == nil && len(args) > len(f.Params)) {
+		return nil, newError(t.Pos, "invalid number of arguments for function %s. Expected %s got %d", f.Signature(), arity(required, len(f.Params), f.Rest != nil), len(args))
+	}
+
+	vals := make([]object.Object, len(f.Params))
+	copy(vals, args)
+
+	for _, n := range named {
+		i := paramIndex(f, n.name)
+		if i < 0 {
+			return nil, newError(t.Pos, "unknown parameter '%s' for function %s", n.name, f.Signature())
+		}
+		if vals[i] != nil {
+			return nil, newError(t.Pos, "parameter '%s' given more than once for function %s", n.name, f.Signature())
+		}
+		vals[i] = n.value
+	}
+
 	env := object.NewChildEnvironment(f.Env)
 
 	for i, p := range f.Params {
-		env.Set(p.Value, args[i])
+		val := vals[i]
+		if val == nil {
+			if f.Defaults[i] == nil {
+				return nil, newError(t.Pos, "missing argument for parameter '%s' of function %s", p, f.Signature())
+			}
+
+			val = Eval(f.Defaults[i], env, stop)
+			if isError(val) {
+				return nil, val
+			}
+		}
+
+		if err := bindPattern(p, val, env); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(f.Params) {
+			rest = append(rest, args[len(f.Params):]...)
+		}
+		env.Set(f.Rest.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
+
+// paramIndex finds the param called name. -1 if there is none
+// only plain identifier params can be named
+func paramIndex(f *object.Function, name string) int {
+	for i, p := range f.Params {
+		if id, ok := p.(*ast.Identifier); ok && id.Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// arity describes how many args a function takes for error messages
+func arity(required, params int, variadic bool) string {
+	switch {
+	case variadic:
+		return fmt.Sprintf("at least %d", required)
+	case required == params:
+		return fmt.Sprintf("%d", params)
+	default:
+		return fmt.Sprintf("%d to %d", required, params)
+	}
+}
+
+// checkRedeclare returns an error if pattern binds a name that is already a constant in env
+func checkRedeclare(pattern ast.Expression, env *object.Environment) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if env.IsLocalConst(pattern.Value) {
+			return newError(pattern.Token.Pos, "cannot redeclare constant '%s'", pattern.Value)
+		}
+	case *ast.ArrayPattern:
+		for _, p := range pattern.Elements {
+			if err := checkRedeclare(p, env); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			return checkRedeclare(pattern.Rest, env)
+		}
+	}
+	return nil
+}
+
+// constPattern marks the names bound by pattern as constants
+func constPattern(pattern ast.Expression, env *object.Environment) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if val, ok := env.Get(pattern.Value); ok {
+			env.SetConst(pattern.Value, val)
+		}
+	case *ast.ArrayPattern:
+		for _, p := range pattern.Elements {
+			constPattern(p, env)
+		}
+		if pattern.Rest != nil {
+			constPattern(pattern.Rest, env)
+		}
 	}
+}
+
+// bindPattern sets the names in an Identifier or ArrayPattern to val in env
+// returns an error if val does not have the shape of the pattern
+func bindPattern(pattern ast.Expression, val object.Object, env *object.Environment) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Set(pattern.Value, val)
+		return nil
+	case *ast.ArrayPattern:
+		array, ok := val.(*object.Array)
+		if !ok {
+			return newError(pattern.Token.Pos, "cannot destructure type '%s' into '%s'", val.Type(), pattern)
+		}
+
+		n := len(pattern.Elements)
+		if len(array.Elements) < n || (!pattern.HasRest && len(array.Elements) != n) {
+			return newError(pattern.Token.Pos, "cannot destructure array of length %d into '%s'", len(array.Elements), pattern)
+		}
 
-	return env
+		for i, p := range pattern.Elements {
+			if err := bindPattern(p, array.Elements[i], env); err != nil {
+				return err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(array.Elements)-n)
+			copy(rest, array.Elements[n:])
+			env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+		}
+		return nil
+	default:
+		return newError(token.Position{}, "cannot bind to '%s'", pattern)
+	}
 }
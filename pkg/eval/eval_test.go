@@ -1,10 +1,18 @@
 package eval
 
 import (
+	"bytes"
+	"io/ioutil"
 	"jacob/dusk/pkg/lexer"
 	"jacob/dusk/pkg/object"
 	"jacob/dusk/pkg/parser"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestArrayIndexExpressions(t *testing.T) {
@@ -96,6 +104,24 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`rest([])`, nil},
 		{`push([], 1)`, []int{1}},
 		{`push(1)`, "wrong number of arguments. got '1', expected '2'"},
+		{`let a = [1]; push(a, 2); a`, []int{1, 2}},
+		{`let a = [1]; let b = a; push(b, 2); a`, []int{1, 2}},
+		{`let a = [1, 2]; pop(a); a`, []int{1}},
+		{`let a = [1, 3]; insert(a, 1, 2)`, []int{1, 2, 3}},
+		{`let a = [1, 2]; insert(a, -1, 3); a`, []int{1, 2, 3}},
+		{`insert([1], 0, 0)`, []int{0, 1}},
+		{`insert([1], 2, 0)`, "index '2' out of bounds of array. Max '1'"},
+		{`let a = [1, 2, 3]; remove(a, 1)`, 2},
+		{`let a = [1, 2, 3]; remove(a, -1); a`, []int{1, 2}},
+		{`remove([], 0)`, "index '0' out of bounds of array. Max '-1'"},
+		{`let a = [1, 2]; clear(a); a`, []int{}},
+		{`let a = [1, 2]; let b = copy(a); push(b, 3); a`, []int{1, 2}},
+		{`let a = freeze([1]); let b = copy(a); push(b, 3); b`, []int{1, 3}},
+		{`let a = [1, 2]; let b = a + [3]; push(a, 4); b`, []int{1, 2, 3}},
+		{`let a = [1, 2]; let f = |arr| { arr[0] = 5 }; f(a); a`, []int{5, 2}},
+		{`insert(freeze([1]), 0, 0)`, "cannot insert into frozen array"},
+		{`remove(freeze([1]), 0)`, "cannot remove from frozen array"},
+		{`clear(freeze([1]))`, "cannot clear frozen array"},
 	}
 
 	for _, tt := range tests {
@@ -193,6 +219,75 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalBitwiseExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ~ 3", 5},
+		{"~5", -6},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"1 | 2 & 3", 3},
+		{"1 << 2 + 1", 8},
+		{"let f = |x| x | 1; f(4)", 5},
+		{"7 / 2", 3},
+		{"-7 / 2", -4},
+		{"7 / -2", -4},
+		{"-7 % 2", 1},
+		{"7 % -2", -1},
+		{"-6 / 2", -3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalBigNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		typ      object.Type
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808", object.BigIntType},
+		{"-9223372036854775807 - 2", "-9223372036854775809", object.BigIntType},
+		{"2 ^ 64", "18446744073709551616", object.BigIntType},
+		{"2 ^ 62", "4611686018427387904", object.IntType},
+		{"4294967296 * 4294967296", "18446744073709551616", object.BigIntType},
+		{"1 << 70", "1180591620717411303424", object.BigIntType},
+		{"bigint('100000000000000000000') / 3", "33333333333333333333", object.BigIntType},
+		{"-bigint(7) / 2", "-4", object.BigIntType},
+		{"bigint(7) % -2", "-1", object.BigIntType},
+		{"bigint(5) == 5", "true", object.BooleanType},
+		{"bigint(5) + 0.5", "5.500000", object.FloatType},
+		{"rational(1, 3) + rational(1, 6)", "1/2", object.RationalType},
+		{"rational('0.1') + rational('0.2') == rational(3, 10)", "true", object.BooleanType},
+		{"rational(2, 4) * 4", "2", object.RationalType},
+		{"rational(2, 3) ^ -2", "9/4", object.RationalType},
+		{"2 ^ -1", "1/2", object.RationalType},
+		{"-2 ^ -3", "-1/8", object.RationalType},
+		{"1 ^ -5", "1", object.RationalType},
+		{"1 ^ 9000000000000", "1", object.IntType},
+		{"rational(1, 4) + 0.25", "0.500000", object.FloatType},
+		{"let a = 1; while a < 2 ^ 65 : a = a * 2; a", "36893488147419103232", object.BigIntType},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Type() != tt.typ {
+			t.Errorf("%s: wrong type. want=%s, got=%s (%s)", tt.input, tt.typ, evaluated.Type(), evaluated)
+			continue
+		}
+		if evaluated.String() != tt.expected {
+			t.Errorf("%s: wrong value. want=%s, got=%s", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
 func TestEvalFloatExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -380,6 +475,41 @@ if (10 > 1) {
 	}
 }
 
+func TestWhileExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while i < 3 { i += 1 }; i", "3"},
+		{"let i = 0; while i < 3: i += 1; i", "3"},
+		{"let f = || { let i = 0; while i < 10 { if i == 3: ret i; i += 1 }; 99 }; f()", "3"},
+		{"let f = || { while true { while true { ret 5 } } }; f()", "5"},
+		{"let i = 0; while i < 3 { i += 1; missing }; i", errorMsg("identifier not found: missing")},
+		{"let f = || { let i = 0; while true { i += 1; if i > 2: i + 'a' } }; f()", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestWhileStop(t *testing.T) {
+	stop := make(chan struct{})
+	done := make(chan object.Object)
+	go func() {
+		p := parser.New(lexer.WithString("let i = 0; while true { i += 1 }", "testeval"))
+		done <- Eval(p.ParseProgram(), object.NewEnvironment(), stop)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Errorf("while loop was not stopped")
+	}
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -433,6 +563,30 @@ func TestErrorHandling(t *testing.T) {
 			`"Hello" - "World"`,
 			"cannot apply operator '-' for type 'string' and 'string'",
 		},
+		{
+			"1 / 0",
+			"cannot divide 1 by 0",
+		},
+		{
+			"1 << -1",
+			"cannot shift 1 by negative count -1",
+		},
+		{
+			"1 << 1000000000000",
+			"result of 1 << 1000000000000 is too large",
+		},
+		{
+			"2 ^ 9000000000000",
+			"result of 2 ^ 9000000000000 is too large",
+		},
+		{
+			"0 ^ -1",
+			"cannot raise 0 to negative power -1",
+		},
+		{
+			"~1.5",
+			"unknown operator '~' for type 'float'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -452,6 +606,49 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 5; a += 2; a", 7},
+		{"let a = 5; a -= 2; a", 3},
+		{"let a = 5; a *= 2; a", 10},
+		{"let a = 7; a /= 2; a", 3},
+		{"let a = 7; a %= 4; a", 3},
+		{"let a = 3; a ^= 3; a", 27},
+		{"let a = 3; a *= 2 + 1", 9},
+		{"let a = [1, 2, 3]; a[1] *= 4; a[1]", 8},
+		{"let a = [1, 2, 3]; a[-1] += 4; a[2]", 7},
+		{"let a = [1, 2, 3]; a[-1] = 9; a[2]", 9},
+		{"let n = 0; let a = [1, 2]; let i = || { n += 1; ret 0 }; a[i!] += 1; a[i!] ^= 2; n", 2},
+		{"let n = 0; let a = [1, 2]; let i = || { n += 1; ret 0 }; a[i!] += 1; a[i!] ^= 2; a[0]", 4},
+		{"let p = || { let age = 5; ret || p }; let x = p!; x.age %= 3; x.age", 2},
+		{"let a = 1; a /= 2.0", "cannot assign variable 'a' of type 'int' to value '0.500000' of type 'float'"},
+		{"let a = 'a'; a *= 2", "cannot apply operator '*' for type 'string' and 'int'"},
+		{"let a = 1; a /= 0", "cannot divide 1 by 0"},
+		{"b += 1", "cannot assign value to variable 'b' that does not exist"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -468,6 +665,51 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a + b", 3},
+		{"let [a, [b, c]] = [1, [2, 3]]; a + b + c", 6},
+		{"let [a, ...rest] = [1, 2, 3]; len(rest)", 2},
+		{"let [a, ...rest] = [1]; len(rest)", 0},
+		{"let [a, ...] = [4, 5, 6]; a", 4},
+		{"let divmod = |a, b| [a / b, a % b]; let [q, r] = divmod(7, 2); q * 10 + r", 31},
+		{"let f = |[x, y], z| x + y + z; f([1, 2], 3)", 6},
+		{"let s = 0; for x in [1, 2, 3]: s += x; s", 6},
+		{"let s = 0; for [k, v] in [[1, 2], [3, 4]] { s += k * v }; s", 14},
+		{"let n = 0; for c in 'héllo': n += 1; n", 5},
+		{"let f = || { for x in [1, 2, 3] { if x == 2: ret x * 10 }; 0 }; f!", 20},
+		{"let [a, b] = [1, 2, 3]", "cannot destructure array of length 3 into '[a, b]'"},
+		{"let [a, b, ...c] = [1]", "cannot destructure array of length 1 into '[a, b, ...c]'"},
+		{"let [a] = 5", "cannot destructure type 'int' into '[a]'"},
+		{"for x in 5: x", "cannot iterate over type 'int'"},
+		{"let f = |[x, y]| x; f([1])", "cannot destructure array of length 1 into '[x, y]'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Pos.Line == 0 {
+				t.Errorf("error has no position. %q", errObj.Message)
+			}
+		}
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "|x| x + 2;"
 
@@ -511,6 +753,108 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestVariadicAndDefaultParams(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = |a, b = 10| a + b; f(1)", 11},
+		{"let f = |a, b = 10| a + b; f(1, 2)", 3},
+		{"let f = |a, b = a * 2| a + b; f(3)", 9},
+		{"let f = |a, ...rest| len(rest); f(1, 2, 3)", 2},
+		{"let f = |a, ...rest| len(rest); f(1)", 0},
+		{"let f = |...xs| xs[-1]; f(4, 5, 6)", 6},
+		{"let f = |a, b, c| a + b + c; let xs = [1, 2, 3]; f(...xs)", 6},
+		{"let f = |a, b, c| a + b + c; f(1, ...[2, 3])", 6},
+		{"let f = |...xs| len(xs); f(...[1, 2], 3, ...[])", 3},
+		{"len([0, ...[1, 2], 3])", 4},
+		{"let f = |a, b = 10| a + b; f()", "invalid number of arguments for function |a, b = 10|. Expected 1 to 2 got 0"},
+		{"let f = |a, b| a + b; f(1, 2, 3)", "invalid number of arguments for function |a, b|. Expected 2 got 3"},
+		{"let f = |a, ...rest| a; f()", "invalid number of arguments for function |a, ...rest|. Expected at least 1 got 0"},
+		{"let f = |a| a; f(...5)", "cannot spread type 'int'. Must be array"},
+		{"...[1]", "'...' can only be used in call arguments or array literals"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = |a, b| a - b; f(b: 1, a: 5)", 4},
+		{"let f = |a, b| a - b; f(5, b: 1)", 4},
+		{"let f = |a, b = 2, c = 3| a * 100 + b * 10 + c; f(1, c: 9)", 129},
+		{"let f = |a, b = a + 1| b; f(a: 4)", 5},
+		{"let f = |a, ...rest| a + len(rest); f(1, 2, 3)", 3},
+		{"let f = |a, b| a; f(a: 1, c: 2)", "unknown parameter 'c' for function |a, b|"},
+		{"let f = |a, b| a; f(1, a: 2)", "parameter 'a' given more than once for function |a, b|"},
+		{"let f = |a, b| a; f(a: 1, a: 2)", "parameter 'a' given more than once for function |a, b|"},
+		{"let f = |a, b| a; f(a: 1)", "missing argument for parameter 'b' of function |a, b|"},
+		{"len(x: [1])", "builtin functions do not take named arguments. got 'x'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Pos.Line == 0 {
+				t.Errorf("error has no position. %q", errObj.Message)
+			}
+		}
+	}
+}
+
+func TestCallArgumentOrder(t *testing.T) {
+	input := `
+let log = [];
+let t = |x| { push(log, x); x };
+let f = |a, b, c| a;
+f(t(1), b: t(2), c: t(3));
+f(c: t(4), a: t(5), b: t(6));
+log`
+
+	evaluated := testEval(input)
+	array, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(array.Elements) != 6 {
+		t.Fatalf("wrong num of elements. want=6, got=%d", len(array.Elements))
+	}
+	for i, elem := range array.Elements {
+		testIntegerObject(t, elem, int64(i+1))
+	}
+}
+
 func TestEnclosingEnvironments(t *testing.T) {
 	input := `
 let first = 10;
@@ -563,13 +907,1050 @@ func TestClassAccess(t *testing.T) {
 	}
 }
 
+func TestClasses(t *testing.T) {
+	animal := `class Animal {
+		let name = nil
+		let legs = 4
+		let init = |name| { self.name = name }
+		let speak = || self.name + " makes a sound"
+	}
+	class Bird < Animal {
+		let legs = 2
+		let speak = || super.speak() + " tweet"
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"class P { let x = 1 }; let p = P!; p.x", 1},
+		{"class P { let x = 1 }; let p = P!; p.x = 5; p.x", 5},
+		{"class P { let x = 1; let inc = || { self.x += 1 } }; let p = P!; p.inc!; p.inc!; p.x", 3},
+		{"class P { let x = 0; let init = |x, y = 2| { self.x = x * y } }; let p = P(3); p.x", 6},
+		{"class P { let x = 0; let init = |x, y = 2| { self.x = x * y } }; let p = P(3, y: 5); p.x", 15},
+		{"class P { let x = 1 }; let a = P!; let b = P!; a.x = 2; b.x", 1},
+		{animal + `let b = Bird("polly"); b.speak!`, "polly makes a sound tweet"},
+		{animal + `let b = Bird("polly"); b.legs`, 2},
+		{animal + `let b = Bird("polly"); b.name`, "polly"},
+		{animal + `let a = Animal("cat"); a.speak!`, "cat makes a sound"},
+		{animal + `Bird("polly")`, "Bird{name: polly, legs: 2}"},
+		{animal + `typeof(Bird("polly"))`, "Bird"},
+		{animal + `typeof(Bird)`, "class"},
+		{`typeof([1])`, "array"},
+		{animal + `is(Bird("polly"), Animal)`, true},
+		{animal + `is(Animal("cat"), Bird)`, false},
+		{animal + `is(Bird("polly"), "Animal")`, true},
+		{animal + `is(1, Animal)`, false},
+		{`is(1.5, "float")`, true},
+		{"class P { let x = 1 }; P(1)", "class P has no init method and takes no arguments"},
+		{"let A = 1; class P < A { }", "cannot inherit from type 'int'. Must be class"},
+		{"class P { let x = 1 }; let p = P!; p.y", "identifier not found in context: y"},
+		{"let x = 1; x.y", "cannot use '.' on type 'int'. Must be function, instance or module"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+				}
+				continue
+			}
+			if evaluated == nil || evaluated.String() != expected {
+				t.Errorf("expected=%q, got=%v", expected, evaluated)
+			}
+		}
+	}
+}
+
+func TestOperatorOverloading(t *testing.T) {
+	vec := `class Vec {
+		let x = 0
+		let y = 0
+		let init = |x, y| { self.x = x; self.y = y }
+		let __add = |o| Vec(self.x + o.x, self.y + o.y)
+		let __mul = |k| Vec(self.x * k, self.y * k)
+		let __rmul = |k| Vec(self.x * k, self.y * k)
+		let __eq = |o| if is(o, Vec): [self.x, self.y] == [o.x, o.y] else false
+		let __lt = |o| self.x < o.x
+		let __index = |i| if i == 0: self.x else self.y
+		let __setindex = |i, v| { if i == 0 { self.x = v } else { self.y = v } }
+		let __len = || 2
+		let __str = || "<" + itoa(self.x + 48) + ", " + itoa(self.y + 48) + ">"
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{vec + "let v = Vec(1, 2) + Vec(3, 4); v.x * 10 + v.y", 46},
+		{vec + "let v = Vec(1, 2) * 3; v.y", 6},
+		{vec + "let v = 3 * Vec(1, 2); v.x", 3},
+		{vec + "Vec(1, 2) == Vec(1, 2)", true},
+		{vec + "Vec(1, 2) != Vec(1, 2)", false},
+		{vec + "Vec(1, 2) != Vec(1, 3)", true},
+		{vec + "Vec(1, 2) == 1", false},
+		{vec + "[Vec(1, 2)] == [Vec(1, 2)]", true},
+		{vec + "Vec(1, 2) < Vec(2, 0)", true},
+		{vec + "Vec(3, 2) > Vec(2, 0)", true},
+		{vec + "Vec(1, 2) > Vec(2, 0)", false},
+		{vec + "let v = Vec(1, 2); v[1]", 2},
+		{vec + "let v = Vec(1, 2); v[0] = 7; v.x", 7},
+		{vec + "let v = Vec(1, 2); v[1] += 5; v.y", 7},
+		{vec + "let v = Vec(1, 2); v += Vec(1, 1); v.y", 3},
+		{vec + "len(Vec(1, 2))", 2},
+		{vec + "Vec(1, 2) - Vec(1, 2)", "cannot apply operator '-' for type 'instance' and 'instance'"},
+		{"class A { let x = 1 }; let a = A!; a == a", true},
+		{"class A { let x = 1 }; A! == A!", false},
+		{"class A { let __add = |o| o.nope }; A! + A!", "identifier not found in context: nope"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	OutStream = &out
+	defer func() { OutStream = os.Stdout }()
+
+	testEval(vec + "println(Vec(1, 2), [1])")
+	if out.String() != "<1, 2>\n[1]\n" {
+		t.Errorf("println did not use __str. got=%q", out.String())
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"match 1 { 1 => 10, 2 => 20 }", 10},
+		{"match 2 { 1 => 10, 2 => 20 }", 20},
+		{"match 2 { 1 => 10, _ => 30 }", 30},
+		{"match 5 { 1..4 => 1, 5..9 => 2 }", 2},
+		{"match 4 { 1..4 => 1, 5..9 => 2 }", 1},
+		{"match -3 { -5..-1 => 1, _ => 2 }", 1},
+		{"match 0.5 { 0..1 => 1, _ => 2 }", 1},
+		{"match 'a' { 1..4 => 1, _ => 2 }", 2},
+		{"match 'b' { 'a' => 1, 'b' => 2 }", 2},
+		{"match nil { true => 1, nil => 2 }", 2},
+		{"match 7 { n => n * 2 }", 14},
+		{"match 7 { n if n > 10 => 1, n if n > 5 => 2, _ => 3 }", 2},
+		{"match [1, 2] { [a] => a, [a, b] => a + b }", 3},
+		{"match [1, 2, 3] { [2, ...] => 0, [1, ...rest] => len(rest) }", 2},
+		{"match [1, [2, 3]] { [a, [b, c]] => a + b + c }", 6},
+		{"match 1 { [a] => a, _ => 0 }", 0},
+		{"match 1 { 1 => { let a = 5; a + 1 } }", 6},
+		{"let a = 1; match 2 { a => a }; a", 1},
+		{"let f = |x| { match x { 1 => { ret 10 } }; 20 }; f(1)", 10},
+		{"match 3 { 1 => 10 }", "non-exhaustive match. no arm matched value '3'"},
+		{"match 3 { n if n.x => 10 }", "cannot use '.' on type 'int'. Must be function, instance or module"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Pos.Line == 0 {
+				t.Errorf("error has no position. %q", errObj.Message)
+			}
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4][1:3]", []int{2, 3}},
+		{"[1, 2, 3, 4][:2]", []int{1, 2}},
+		{"[1, 2, 3, 4][2:]", []int{3, 4}},
+		{"[1, 2, 3, 4][:]", []int{1, 2, 3, 4}},
+		{"[1, 2, 3, 4][-2:]", []int{3, 4}},
+		{"[1, 2, 3, 4][:-1]", []int{1, 2, 3}},
+		{"[1, 2, 3, 4][1:100]", []int{2, 3, 4}},
+		{"[1, 2, 3, 4][-100:1]", []int{1}},
+		{"[1, 2, 3, 4][3:1]", []int{}},
+		{"[1, 2, 3, 4, 5][::2]", []int{1, 3, 5}},
+		{"[1, 2, 3, 4, 5][1::2]", []int{2, 4}},
+		{"[1, 2, 3, 4][::-1]", []int{4, 3, 2, 1}},
+		{"[1, 2, 3, 4][2::-1]", []int{3, 2, 1}},
+		{"[1, 2, 3, 4][:0:-2]", []int{4, 2}},
+		{"[][1:]", []int{}},
+		{"let a = [1, 2, 3]; let b = a[1:]; b[0] = 5; a", []int{1, 5, 3}},
+		{"let a = [1, 2, 3]; let b = a[:1]; push(b, 9); a", []int{1, 2, 3}},
+		{"let a = [1, 2, 3, 4]; a[1:3] = [9]; a", []int{1, 9, 4}},
+		{"let a = [1, 2]; a[1:1] = [7, 8]; a", []int{1, 7, 8, 2}},
+		{"let a = [1, 2, 3, 4]; a[::2] = [0, 0]; a", []int{0, 2, 0, 4}},
+		{"let a = [1, 2, 3]; a[:] = a[::-1]; a", []int{3, 2, 1}},
+		{"let a = [1, 2, 3]; a[::-1] = a; a", []int{3, 2, 1}},
+		{"let a = [1, 2, 3]; a[1:] += [4]; a", []int{1, 2, 3, 4}},
+		{"let a = [1, 2, 3]; a[1:] = [5]", []int{5}},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[-3:]`, "llo"},
+		{`"hello"[10:]`, ""},
+		{`"héllo"[::-1]`, "olléh"},
+		{`"héllo"[1::2]`, "él"},
+		{"[1, 2][::0]", errorMsg("slice step cannot be 0")},
+		{"[1, 2]['a':]", errorMsg("slice index must be type 'int'. got type 'string'")},
+		{"1[1:]", errorMsg("slice operator not supported on type 'int'")},
+		{"let a = [1, 2, 3]; a[::2] = [1]", errorMsg("cannot assign array of length 1 to slice of length 2")},
+		{"let a = [1, 2, 3]; a[1:] = 1", errorMsg("can only assign type 'array' to a slice. got type 'int'")},
+		{"let a = 'abc'; a[1:] = 'd'", errorMsg("slice operator assign not supported on type 'string'")},
+		{"let a = freeze([1, 2]); a[1:] = [3]", errorMsg("cannot assign slice of frozen array")},
+		{"let a = freeze([1, 2]); let b = a[1:]; b[0] = 3", errorMsg("cannot assign index of frozen array")},
+	}
+
+	for _, tt := range tests {
+		expected, ok := tt.expected.([]int)
+		if !ok {
+			testExpected(t, tt.input, tt.expected)
+			continue
+		}
+
+		evaluated := testEval(tt.input)
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if len(array.Elements) != len(expected) {
+			t.Errorf("wrong num of elements for %q. want=%d, got=%d", tt.input, len(expected), len(array.Elements))
+			continue
+		}
+		for i, expectedElem := range expected {
+			testIntegerObject(t, array.Elements[i], int64(expectedElem))
+		}
+	}
+}
+
+func TestConstAndFreeze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const a = 5; a", 5},
+		{"const a = 5; let f = || a * 2; f!", 10},
+		{"const a = 5; let f = || { let a = 1; a = 2; a }; f!", 2},
+		{"let a = 1; const a = 2; a", 2},
+		{"const [a, b, ...c] = [1, 2, 3]; a + b + len(c)", 4},
+		{"const a = [1]; push(a, 2); len(a)", 2},
+		{"let a = freeze([1, 2]); len(push(rest(a), 3))", 2},
+		{"let a = freeze([1, [2]]); a = [3]; a[0]", 3},
+		{"class P { const x = 1; let y = 2 }; let p = P!; p.y = 3; p.y + p.x", 4},
+		{"const a = 5; a = 6", "cannot assign to constant 'a'"},
+		{"const a = 5; a += 1", "cannot assign to constant 'a'"},
+		{"const a = 5; let f = || { a = 1 }; f!", "cannot assign to constant 'a'"},
+		{"const [a, b] = [1, 2]; b = 3", "cannot assign to constant 'b'"},
+		{"class P { const x = 1 }; let p = P!; p.x = 2", "cannot assign to constant 'x'"},
+		{"let a = freeze([1, 2]); a[0] = 5", "cannot assign index of frozen array"},
+		{"let a = freeze([1, [2]]); a[1][0] += 1", "cannot assign index of frozen array"},
+		{"let a = freeze([1, 2]); set(a, 0, 5)", "cannot set index of frozen array"},
+		{"let a = freeze([1, 2]); push(a, 5)", "cannot push to frozen array"},
+		{"let a = freeze([1, 2]); pop(a)", "cannot pop from frozen array"},
+		{"set([1], 3, 5)", "index '3' out of bounds of array. Max '0'"},
+		{"const x = 1; let x = 2; x = 3", "cannot redeclare constant 'x'"},
+		{"const x = 1; const x = 2", "cannot redeclare constant 'x'"},
+		{"const x = 1; let [a, x] = [2, 3]", "cannot redeclare constant 'x'"},
+		{"class P { const x = 1 }; class C < P { let x = 2 }; C!", "cannot redeclare constant 'x'"},
+		{"const P = 1; class P {}", "cannot redeclare constant 'P'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Pos.Line == 0 {
+				t.Errorf("error has no position. %q", errObj.Message)
+			}
+		}
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"map([1, 2, 3], |x| x * 2)", "[2, 4, 6]"},
+		{"map('ab', |c| c + c)", "[aa, bb]"},
+		{"filter([1, 2, 3, 4], |x| x % 2 == 0)", "[2, 4]"},
+		{"reduce([1, 2, 3], |a, b| a + b)", "6"},
+		{"reduce([1, 2, 3], |a, b| a + b, 10)", "16"},
+		{"reduce([], |a, b| a + b, 0)", "0"},
+		{"let n = 0; each([1, 2], |x| n += x); n", "3"},
+		{"any([1, 2, 3], |x| x > 2)", "true"},
+		{"all([1, 2, 3], |x| x > 2)", "false"},
+		{"find([1, 2, 3], |x| x > 1)", "2"},
+		{"index_of([1, 2, 3], 3)", "2"},
+		{"index_of([1, 2, 3], 4)", "-1"},
+		{"contains([1, [2]], [2])", "true"},
+		{"contains('hello', 'ell')", "true"},
+		{"zip([1, 2, 3], ['a', 'b'])", "[[1, a], [2, b]]"},
+		{"enumerate(['a', 'b'])", "[[0, a], [1, b]]"},
+		{"flatten([1, [2, [3]]])", "[1, 2, 3]"},
+		{"flatten([1, [2, [3]]], 1)", "[1, 2, [3]]"},
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{"sort([3, 1, 2], |a, b| a > b)", "[3, 2, 1]"},
+		{"sort(['b', 'a'])", "[a, b]"},
+		{"let a = [2, 1]; sort(a); a", "[2, 1]"},
+		{"sort_by(['ccc', 'a', 'bb'], len)", "[a, bb, ccc]"},
+		{"uniq([1, 2, 1, 3, 2])", "[1, 2, 3]"},
+		{"group_by([1, 2, 3, 4], |x| x % 2 == 0)", "{false: [1, 3], true: [2, 4]}"},
+		{"sum([1, 2, 3.5])", "6.500000"},
+		{"sum([])", "0"},
+		{"min([3, 1, 2])", "1"},
+		{"max([3, 1, 2])", "3"},
+		{"reverse([1, 2, 3])", "[3, 2, 1]"},
+		{"reverse('héllo')", "olléh"},
+		{"let m = hashmap(); m['a'] = 1; m['b'] = 2; m", "{a: 1, b: 2}"},
+		{"let m = hashmap([['a', 1], [2, 'b']]); m[2]", "b"},
+		{"let m = hashmap([['a', 1]]); m['x']", "nil"},
+		{"let m = hashmap([['a', 1]]); m['a'] += 4; m['a']", "5"},
+		{"let m = hashmap([['a', 1], ['b', 2]]); keys(m)", "[a, b]"},
+		{"let m = hashmap([['a', 1], ['b', 2]]); values(m)", "[1, 2]"},
+		{"let m = hashmap([['a', 1], ['b', 2]]); remove(m, 'a'); m", "{b: 2}"},
+		{"let m = hashmap([['a', 1], ['b', 2]]); len(m)", "2"},
+		{"let m = hashmap([['a', 1]]); contains(m, 'a')", "true"},
+		{"hashmap([[1, 2]]) == hashmap([[1, 2]])", "true"},
+		{"let m = hashmap(); m[1] = 'a'; m[1.0]", "a"},
+		{"let m = hashmap(); m[2.0] = 'a'; m[2] = 'b'; m", "{2.000000: b}"},
+		{"let m = hashmap([[1.5, 'a']]); m[1.5]", "a"},
+		{"hashmap([[1, 2]]) != hashmap([[1, 3]])", "true"},
+		{"let s = 0; for [k, v] in hashmap([['a', 1], ['b', 2]]): s += v; s", "3"},
+		{"reduce([], |a, b| a + b)", errorMsg("cannot reduce empty array without an initial value")},
+		{"map([1], 2)", errorMsg("second argument to 'map' must be a function. got 'int'")},
+		{"contains(1, 1)", errorMsg("argument to 'contains' not supported, got 'int'")},
+		{"class A { let __eq = |o| 1 / 0 }; contains([A!], 1)", errorMsg("cannot divide 1 by 0")},
+		{"sort([1, 'a'])", errorMsg("cannot compare type 'string' and 'int'")},
+		{"let m = hashmap(); m[[1]] = 2", errorMsg("cannot use type 'array' as a map key")},
+		{"let m = hashmap(); m['a'] += 1", errorMsg("key 'a' not in map")},
+		{"let m = freeze(hashmap()); m['a'] = 1", errorMsg("cannot assign key of frozen map")},
+		{"let m = freeze(hashmap([['a', 1]])); remove(m, 'a')", errorMsg("cannot remove from frozen map")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("ÀBC")`, "àbc"},
+		{`trim("  hi\n ")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`trim_left("  hi ")`, "hi "},
+		{`trim_right("  hi ")`, "  hi"},
+		{`trim_right("hi!?", "?!")`, "hi"},
+		{`starts_with("héllo", "hé")`, "true"},
+		{`ends_with("héllo", "x")`, "false"},
+		{`index("héllo", "l")`, "3"},
+		{`let s = "héllo world"; s[index(s, "world"):]`, "world"},
+		{`index("hello", "z")`, "-1"},
+		{`replace("aaa", "a", "b")`, "bbb"},
+		{`replace("aaa", "a", "b", 2)`, "bba"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`pad_left("é", 3)`, "  é"},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_right("ab", 4, "·")`, "ab··"},
+		{`pad_left("abcd", 2)`, "abcd"},
+		{`lines("a\nb\n\nc\n")`, "[a, b, , c]"},
+		{`lines("")`, "[]"},
+		{`chars("héy")`, "[h, é, y]"},
+		{`format("%d + %d = %d", 1, 2, 3)`, "1 + 2 = 3"},
+		{`format("%5d|%-5d|%05d", 1, 2, 3)`, "    1|2    |00003"},
+		{`format("%.2f %f", 3.14159, 1)`, "3.14 1.000000"},
+		{`format("%-4s|%4s|%.2s", "é", "日本", "héllo")`, "é   |  日本|hé"},
+		{`format("%v %s", [1, 2], true)`, "[1, 2] true"},
+		{`format("100%%")`, "100%"},
+		{`format("%d", bigint(5) ^ 30)`, "931322574615478515625"},
+		{`class P { let __str = || "p" }; format("<%s>", P!)`, "<p>"},
+		{`format("%d", "a")`, errorMsg("verb '%d' needs an int. got 'string'")},
+		{`format("%f", "a")`, errorMsg("verb '%f' needs a number. got 'string'")},
+		{`format("%d %d", 1)`, errorMsg("missing argument for verb '%d' in format")},
+		{`format("%d", 1, 2)`, errorMsg("format has 1 verbs but got 2 arguments")},
+		{`format("%q", 1)`, errorMsg("unknown verb '%q' in format")},
+		{`format("%5")`, errorMsg("format ends with an incomplete verb '%5'")},
+		{`upper(1)`, errorMsg("argument 1 to 'upper' must be a string. got 'int'")},
+		{`trim("a", "b", "c")`, errorMsg("wrong number of arguments. got '3', expected '1 or 2'")},
+		{`repeat("a", -1)`, errorMsg("cannot repeat string a negative number of times. got '-1'")},
+		{`repeat("a", 9223372036854775807)`, errorMsg("result of 'repeat' is too large")},
+		{`repeat("ab", 4611686018427387904)`, errorMsg("result of 'repeat' is too large")},
+		{`pad_left("a", 9223372036854775807)`, errorMsg("result of 'pad_left' is too large")},
+		{`pad_right("a", 9223372036854775807, "·")`, errorMsg("result of 'pad_right' is too large")},
+		{`pad_left("a", 3, "ab")`, errorMsg("third argument to 'pad_left' must be a single character. got 'ab'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestConversionsAndMath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`int("42") + 1`, "43"},
+		{`int(" -7 ")`, "-7"},
+		{`int(3.9)`, "3"},
+		{`int(-3.9)`, "-3"},
+		{`int(rational(7, 2))`, "3"},
+		{`int(true)`, "1"},
+		{`int("99999999999999999999")`, "99999999999999999999"},
+		{`typeof(int(bigint(5)))`, "int"},
+		{`float("2.5")`, "2.500000"},
+		{`float(3)`, "3.000000"},
+		{`float(rational(1, 4))`, "0.250000"},
+		{`str(12) + "!"`, "12!"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`class P { let __str = || "p" }; str(P!)`, "p"},
+		{`bool(0)`, "false"},
+		{`bool("a")`, "true"},
+		{`parse_int("ff", 16)`, "255"},
+		{`parse_int("-101", 2)`, "-5"},
+		{`parse_int("0x1f", 0)`, "31"},
+		{`parse_int("12")`, "12"},
+		{`math.sqrt(16)`, "4.000000"},
+		{`math.sqrt(bigint(4))`, "2.000000"},
+		{`math.floor(2.7)`, "2.000000"},
+		{`math.floor(3)`, "3"},
+		{`math.ceil(2.1)`, "3.000000"},
+		{`math.round(2.5)`, "3.000000"},
+		{`math.abs(-3)`, "3"},
+		{`math.abs(-2.5)`, "2.500000"},
+		{`math.abs(rational(-1, 2))`, "1/2"},
+		{`math.abs(-9223372036854775807 - 1)`, "9223372036854775808"},
+		{`math.cos(0)`, "1.000000"},
+		{`math.atan2(0, 1)`, "0.000000"},
+		{`math.log(math.e)`, "1.000000"},
+		{`math.log(8, 2)`, "3.000000"},
+		{`math.log10(1000)`, "3.000000"},
+		{`math.exp(0)`, "1.000000"},
+		{`math.pi`, "3.141593"},
+		{`math.is_nan(math.nan)`, "true"},
+		{`math.is_nan(1)`, "false"},
+		{`math.is_inf(-math.inf)`, "true"},
+		{`let m = math; m.sqrt(9)`, "3.000000"},
+		{`let math = [1]; len(math)`, "1"},
+		{`typeof(math)`, "module"},
+		{`int("abc")`, errorMsg("cannot convert 'abc' to int")},
+		{`int(math.nan)`, errorMsg("cannot convert 'NaN' to int")},
+		{`float("x")`, errorMsg("cannot convert 'x' to float")},
+		{`int([1])`, errorMsg("argument to 'int' not supported, got 'array'")},
+		{`parse_int("12", 1)`, errorMsg("base must be 0 or between 2 and 36. got '1'")},
+		{`parse_int("19", 8)`, errorMsg("cannot parse '19' as an int in base 8")},
+		{`math.sqrt("a")`, errorMsg("argument to 'sqrt' must be a number. got 'string'")},
+		{`math.pi = 3`, errorMsg("cannot assign to constant 'pi'")},
+		{`math.nope`, errorMsg("identifier not found in context: nope")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let r = re("\d+"); r.match("ab12")`, "true"},
+		{`let r = re("^\d+$"); r.match("ab12")`, "false"},
+		{`let r = re("\d+"); r.find("ab12c3")`, "12"},
+		{`let r = re("\d+"); r.find("abc")`, "nil"},
+		{`let r = re("\d+"); r.find_all("a1b22c333")`, "[1, 22, 333]"},
+		{`let r = re("\d+"); r.find_all("a1b22c333", 2)`, "[1, 22]"},
+		{`let r = re("\d+"); r.find_all("abc")`, "[]"},
+		{`let r = re("(\w+)@(\w+)(!)?"); r.captures("me@host")`, "[me@host, me, host, nil]"},
+		{`let r = re("(\w+)@(\w+)"); r.captures("nope")`, "nil"},
+		{`let r = re("(?P<user>\w+)@(?P<host>\w+)"); r.captures("me@host")['host']`, "host"},
+		{`let r = re("(?P<user>\w+)@(\w+)"); r.captures("me@host")`, "{user: me}"},
+		{`let r = re("\d+"); r.replace("a1b22", |m| int(m) * 2)`, "a2b44"},
+		{`let r = re("(\w)(\d)"); r.replace("a1 b2", "$2$1")`, "1a 2b"},
+		{`let r = re("ü+"); r.replace("füüx", "u")`, "fux"},
+		{`let r = re(",\s*"); r.split("a, b,c")`, "[a, b, c]"},
+		{`let r = re(",\s*"); r.split("a, b,c", 2)`, "[a, b,c]"},
+		{`let r = re("a.c"); r.pattern`, "a.c"},
+		{`let r = re("a.c"); r`, "re('a.c')"},
+		{`re("x+") == re("x+")`, "true"},
+		{`typeof(re("x"))`, "regex"},
+		{`re("(a")`, errorMsg("invalid regex '(a'. error parsing regexp: missing closing ): `(a`")},
+		{`re(1)`, errorMsg("argument 1 to 're' must be a string. got 'int'")},
+		{`let r = re("\d"); r.replace("a1", |m| m + 1)`, errorMsg("cannot apply operator '+' for type 'string' and 'int'")},
+		{`let r = re("\d"); r.replace("a1", 1)`, errorMsg("argument 2 to 'replace' must be a string or function. got 'int'")},
+		{`let r = re("\d"); r.match = 1`, errorMsg("cannot assign to constant 'match'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	compile := func(pattern string) *object.Regex {
+		r, ok := re(nil, &object.String{Value: pattern}).(*object.Regex)
+		if !ok {
+			t.Fatalf("re(%q) did not make a regex", pattern)
+		}
+		return r
+	}
+
+	first := compile("cache0")
+	for i := 1; i < maxCachedRegexes; i++ {
+		compile("cache" + strconv.Itoa(i))
+	}
+	// using the first pattern again makes the second the oldest
+	if compile("cache0") != first {
+		t.Errorf("cached regex was compiled again")
+	}
+	compile("new")
+
+	if _, ok := regexCache.patterns["cache1"]; ok {
+		t.Errorf("oldest pattern was not dropped from the cache")
+	}
+	if compile("cache0") != first {
+		t.Errorf("recently used pattern was dropped from the cache")
+	}
+	if len(regexCache.patterns) != maxCachedRegexes {
+		t.Errorf("cache has %d patterns. expected %d", len(regexCache.patterns), maxCachedRegexes)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`json_parse('{"a": 1, "b": [true, null, "x"]}')`, "{a: 1, b: [true, nil, x]}"},
+		{`json_parse('{"b": 1, "a": 2}')`, "{b: 1, a: 2}"},
+		{`typeof(json_parse('3'))`, "int"},
+		{`typeof(json_parse('3.0'))`, "float"},
+		{`typeof(json_parse('1e2'))`, "float"},
+		{`json_parse('123456789012345678901')`, "123456789012345678901"},
+		{`json_parse(' "hé" ')`, "hé"},
+		{`json_parse('[]')`, "[]"},
+		{`json_stringify(json_parse('{"a":[1,2.5,"x"],"b":{}}'))`, `{"a":[1,2.5,"x"],"b":{}}`},
+		{`json_stringify([nil, true, "<a>"])`, `[null,true,"<a>"]`},
+		{`json_stringify(hashmap([[1, 'a']]))`, `{"1":"a"}`},
+		{`json_stringify(rational(1, 4))`, "0.25"},
+		{`json_stringify([1.0, -2.0, 2.5])`, "[1.0,-2.0,2.5]"},
+		{`typeof(json_parse(json_stringify(3.0)))`, "float"},
+		{`json_stringify([1, [2]], 1)`, "[\n 1,\n [\n  2\n ]\n]"},
+		{`json_stringify(hashmap([['a', 1]]), '\t')`, "{\n\t\"a\": 1\n}"},
+		{`let a = [[1]]; json_stringify([a, a])`, "[[[1]],[[1]]]"},
+		{`let a = [1]; push(a, a); json_stringify(a)`, errorMsg("cannot convert array that contains itself to json")},
+		{`let m = hashmap(); m['m'] = m; json_stringify(m)`, errorMsg("cannot convert map that contains itself to json")},
+		{`json_stringify(math.nan)`, errorMsg("cannot convert 'NaN' to json")},
+		{`json_stringify(|x| x)`, errorMsg("cannot convert type 'function' to json")},
+		{`json_stringify(hashmap([[1, 'a'], ['1', 'b']]))`, errorMsg("cannot convert map with more than one key written as '1' to json")},
+		{`json_parse('{"a": 1,\n "b": x}')`, errorMsg("invalid json at line 2, column 7. invalid character 'x' looking for beginning of value")},
+		{`json_parse('[1, 2')`, errorMsg("invalid json at line 1, column 6. unexpected end of input")},
+		{`json_parse('[1] 2')`, errorMsg("invalid json at line 1, column 5. unexpected data after value")},
+		{`json_parse('{"é": ü}')`, errorMsg("invalid json at line 1, column 7. invalid character 'ü' looking for beginning of value")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestFileSystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dusk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// each input runs in a fresh empty directory named by 'dir'
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`out(dir + "/a", "hi"); in(dir + "/a")`, "hi"},
+		{`let f = fs.open(dir + "/a", "w"); let n = f.write("héllo"); f.close(); n`, "6"},
+		{`out(dir + "/a", "one\ntwo\n"); let f = fs.open(dir + "/a"); [f.read_line(), f.read_line(), f.read_line()]`, "[one, two, nil]"},
+		{`out(dir + "/a", "abc"); let f = fs.open(dir + "/a"); [f.read(2), f.read(), f.read(1)]`, "[ab, c, nil]"},
+		{`out(dir + "/a", "a"); fs.append(dir + "/a", "b"); fs.append(dir + "/b", "c"); in(dir + "/a") + in(dir + "/b")`, "abc"},
+		{`out(dir + "/a", "a"); let f = fs.open(dir + "/a", "a"); f.write("b"); f.close(); in(dir + "/a")`, "ab"},
+		{`out(dir + "/a", ""); [fs.exists(dir + "/a"), fs.exists(dir + "/b")]`, "[true, false]"},
+		{`out(dir + "/a", "abc"); let s = fs.stat(dir + "/a"); [s['name'], s['size'], s['dir']]`, "[a, 3, false]"},
+		{`fs.mkdir(dir + "/x/y"); fs.stat(dir + "/x")['dir']`, "true"},
+		{`out(dir + "/b", ""); out(dir + "/a", ""); fs.mkdir(dir + "/c"); fs.list_dir(dir)`, "[a, b, c]"},
+		{`out(dir + "/a.txt", ""); out(dir + "/b.dusk", ""); len(fs.glob(dir + "/*.txt"))`, "1"},
+		{`out(dir + "/a", "x"); fs.rename(dir + "/a", dir + "/b"); [fs.exists(dir + "/a"), in(dir + "/b")]`, "[false, x]"},
+		{`out(dir + "/a", ""); fs.remove(dir + "/a"); fs.exists(dir + "/a")`, "false"},
+		{`out(dir + "/a", "")`, "nil"},
+		{`let f = fs.open(dir + "/a", "w"); f.close(); f.write("x")`, errorMsg("write DIR/a: file already closed")},
+		{`fs.open(dir + "/nope")`, errorMsg("open DIR/nope: no such file or directory")},
+		{`fs.open(dir + "/a", "x")`, errorMsg("unknown file mode 'x'. expected one of r, w, a, r+, w+ or a+")},
+		{`fs.remove(dir + "/nope")`, errorMsg("remove DIR/nope: no such file or directory")},
+		{`fs.list_dir(dir + "/nope")`, errorMsg("open DIR/nope: no such file or directory")},
+		{`fs.stat(dir + "/nope")`, errorMsg("stat DIR/nope: no such file or directory")},
+		{`in(dir + "/nope")`, errorMsg("open DIR/nope: no such file or directory")},
+		{`out(dir + "/nope/a", "")`, errorMsg("open DIR/nope/a: no such file or directory")},
+		{`out(dir + "/a")`, errorMsg("out takes two arguments. given '1'")},
+		{`out(dir + "/a", 1)`, errorMsg("second argument to 'out' not supported, got 'int'")},
+	}
+
+	for i, tt := range tests {
+		testDir := filepath.Join(dir, strconv.Itoa(i))
+		if err := os.Mkdir(testDir, 0777); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := tt.expected
+		if msg, ok := expected.(errorMsg); ok {
+			expected = errorMsg(strings.Replace(string(msg), "DIR", testDir, 1))
+		}
+		testExpected(t, `let dir = "`+testDir+`"; `+tt.input, expected)
+	}
+}
+
+func TestInput(t *testing.T) {
+	defer func() { InStream = os.Stdin }()
+
+	tests := []struct {
+		input    string
+		stdin    string
+		expected string
+	}{
+		{"[readln!, readln!, readln!]", "one\r\ntwo", "[one, two, nil]"},
+		{"[readc!, readc!, readln!]", "héllo\nworld", "[h, é, llo]"},
+		{"[readc!, readln!, readln!]", "ab\ncd\n", "[a, b, cd]"},
+		{"[read!, read!, readln!, read!]", "  ab cd  ef\ngh", "[ab, cd,   ef, gh]"},
+		{"[readln!, readall!, readall!]", "a\nb\nc", "[a, b\nc, nil]"},
+		{"[eof!, readc!, eof!, readc!]", "a", "[false, a, true, nil]"},
+		{"[read!, eof!]", "", "[nil, true]"},
+		{"let n = 0; while !eof! { readln!; n += 1 }; n", "1\n2\n3\n", "3"},
+	}
+
+	for _, tt := range tests {
+		InStream = strings.NewReader(tt.stdin)
+		evaluated := testEval(tt.input)
+
+		if _, ok := evaluated.(*object.Error); ok || evaluated == nil || evaluated.String() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestOSNamespace(t *testing.T) {
+	exitCode := -1
+	Args = []string{"a", "b"}
+	Exit = func(code int) { exitCode = code }
+	defer func() {
+		Args = nil
+		Exit = os.Exit
+		AllowOS = true
+	}()
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`os.args()`, "[a, b]"},
+		{`os.set_env("DUSK_TEST_VAR", "hi"); os.env("DUSK_TEST_VAR")`, "hi"},
+		{`os.env("DUSK_TEST_MISSING")`, "nil"},
+		{`os.set_env("DUSK_TEST_VAR", "hi"); os.env()['DUSK_TEST_VAR']`, "hi"},
+		{`typeof(os.cwd())`, "string"},
+		{`typeof(os.hostname())`, "string"},
+		{`os.exec("sh", ["-c", "echo out; echo err >&2; exit 3"])`, "{stdout: out\n, stderr: err\n, code: 3}"},
+		{`os.exec("cat", [], "piped")['stdout']`, "piped"},
+		{`os.exec("sh", ["-c", "echo $0", 5])['stdout']`, "5\n"},
+		{`os.exit(2)`, "nil"},
+		{`os.exec("dusk-test-no-such-command")`, errorMsg(`exec: "dusk-test-no-such-command": executable file not found in $PATH`)},
+		{`os.exec("sh", "-c")`, errorMsg("argument 2 to 'exec' must be an array. got 'string'")},
+		{`os.chdir("/dusk-test-no-such-dir")`, errorMsg("chdir /dusk-test-no-such-dir: no such file or directory")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+
+	if exitCode != 2 {
+		t.Errorf("os.exit did not exit with 2. got=%d", exitCode)
+	}
+
+	AllowOS = false
+	disabled := []struct {
+		input    string
+		expected string
+	}{
+		{`os.env("HOME")`, "os functions are disabled"},
+		{`fs.remove("dusk-test-no-such-file")`, "file system functions are disabled"},
+		{`fs.open("dusk-test-no-such-file", "w")`, "file system functions are disabled"},
+		{`in("dusk-test-no-such-file")`, "file system functions are disabled"},
+		{`out("dusk-test-no-such-file", "x")`, "file system functions are disabled"},
+	}
+
+	for _, tt := range disabled {
+		testExpected(t, tt.input, errorMsg(tt.expected))
+	}
+}
+
+func TestTime(t *testing.T) {
+	// times are parsed in utc so the tests don't depend on the local zone
+	const day = `let t = time.parse("2024-02-28 13:05:09", time.datetime); `
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{day + "t", "2024-02-28 13:05:09 +0000 UTC"},
+		{day + "[t.year, t.month, t.day, t.hour, t.minute, t.second, t.weekday, t.yearday, t.zone]", "[2024, 2, 28, 13, 5, 9, Wednesday, 59, UTC]"},
+		{day + "t.unix", "1709125509"},
+		{day + "time.format(t, 'Jan 2, 2006 at 3:04pm')", "Feb 28, 2024 at 1:05pm"},
+		{day + "time.format(t, time.date_only)", "2024-02-28"},
+		{day + "t + time.hours(36)", "2024-03-01 01:05:09 +0000 UTC"},
+		{day + "time.minutes(1) + t", "2024-02-28 13:06:09 +0000 UTC"},
+		{day + "t - time.seconds(9)", "2024-02-28 13:05:00 +0000 UTC"},
+		{day + "(t + time.hours(2)) - t", "2h0m0s"},
+		{day + "let d = (t + time.ms(1500)) - t; [d.seconds, d.ms]", "[1.500000, 1500]"},
+		{day + "[t + time.ms(1) > t, t < t, t == t + time.ms(0), t != t]", "[true, false, true, false]"},
+		{day + "time.in_zone(t, 'Asia/Tokyo')", "2024-02-28 22:05:09 +0900 JST"},
+		{"time.parse('2024-07-01 08:00', '2006-01-02 15:04', 'America/New_York')", "2024-07-01 08:00:00 -0400 EDT"},
+		{"let t = time.unix(0); time.in_zone(t, 'UTC')", "1970-01-01 00:00:00 +0000 UTC"},
+		{"time.unix() > 1000000000", "true"},
+		{"typeof(time.now())", "time"},
+		{"time.duration('1h30m') / 2", "45m0s"},
+		{"2 * time.seconds(1)", "2s"},
+		{"1.5 * time.minutes(1)", "1m30s"},
+		{"time.hours(1) * 10000000000.0", errorMsg("duration out of range scaling 1h0m0s")},
+		{"time.hours(1) / 0.0000000001", errorMsg("duration out of range scaling 1h0m0s")},
+		{"time.hours(9223372036854)", errorMsg("9223372036854 hours is out of range of a duration")},
+		{"time.ms(-9223372036855)", errorMsg("-9223372036855 ms is out of range of a duration")},
+		{"time.hours(10000000000.5)", errorMsg("10000000000.500000 hours is out of range of a duration")},
+		{"time.seconds(math.nan)", errorMsg("NaN seconds is out of range of a duration")},
+		{"time.hours(2562047)", "2562047h0m0s"},
+		{"2 - time.seconds(1)", errorMsg("cannot apply operator '-' for type 'int' and 'duration'")},
+		{"time.minutes(2) * 1.5", "3m0s"},
+		{"time.seconds(3) / time.seconds(2)", "1.500000"},
+		{"time.seconds(1) - time.ms(250)", "750ms"},
+		{"[time.seconds(1) > time.ms(999), time.seconds(60) == time.minutes(1)]", "[true, true]"},
+		{"time.seconds(0.5)", "500ms"},
+		{"let c = time.clock(); sleep(time.ms(2)); time.clock() - c > time.ms(1)", "true"},
+		{"time.seconds(1) == 1", "false"},
+		{"time.parse('x', '2006')", errorMsg(`parsing time "x" as "2006": cannot parse "x" as "2006"`)},
+		{"time.parse('2024', '2006', 'Nowhere/Zone')", errorMsg("unknown time zone 'Nowhere/Zone'")},
+		{"let t = time.now(); time.in_zone(t, 'Nowhere/Zone')", errorMsg("unknown time zone 'Nowhere/Zone'")},
+		{"time.duration('1x')", errorMsg(`time: unknown unit "x" in duration "1x"`)},
+		{"time.format(1, time.rfc3339)", errorMsg("argument 1 to 'format' must be a time. got 'int'")},
+		{"time.seconds(1) / 0", errorMsg("cannot divide 1s by 0")},
+		{"let t = time.now(); t + t", errorMsg("cannot apply operator '+' for type 'time' and 'time'")},
+		{"let t = time.now(); t.year = 1", errorMsg("cannot assign to constant 'year'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	draws := "[rand(), rand(1, 100), rand_int(10), rand_int(-5, 5), rand_float(), rand_float(1, 2), choice([1, 2, 3]), shuffle([1, 2, 3, 4]), sample([1, 2, 3, 4], 2)]"
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"seed(42); let a = " + draws + "; seed(42); let b = " + draws + "; str(a) == str(b)", "true"},
+		{"seed(1); let a = rand_int(1000000); seed(2); a == rand_int(1000000)", "false"},
+		{"seed(7)", "nil"},
+		{"let n = rand_int(3, 4); n", "3"},
+		{"let n = rand(3, 4); n", "3"},
+		{"let f = rand_float(2, 3); [f > 2, f < 3]", "[true, true]"},
+		{"choice([5])", "5"},
+		{"choice('a')", "a"},
+		{"sort(shuffle([3, 1, 2]))", "[1, 2, 3]"},
+		{"let a = [1, 2, 3]; shuffle(a); a", "[1, 2, 3]"},
+		{"sort(sample([3, 1, 2], 3))", "[1, 2, 3]"},
+		{"sample([1, 2], 0)", "[]"},
+		{"let n = rand_int(-9223372036854775807 - 1, 9223372036854775807); typeof(n)", "int"},
+		{"let n = rand_int(-4611686018427387904 * 2, 9223372036854775807); typeof(n)", "int"},
+		{"rand(5, 5)", errorMsg("max must be greater than min for 'rand'. got min '5' and max '5'")},
+		{"rand_int(0)", errorMsg("max must be greater than min for 'rand_int'. got min '0' and max '0'")},
+		{"rand_int(3, 1)", errorMsg("max must be greater than min for 'rand_int'. got min '3' and max '1'")},
+		{"rand_float(1.5, 1)", errorMsg("max must be greater than min for 'rand_float'. got min '1.500000' and max '1'")},
+		{"rand(1)", errorMsg("wrong number of arguments. got '1', expected '0 or 2'")},
+		{"rand_int('a')", errorMsg("argument 1 to 'rand_int' must be an int. got 'string'")},
+		{"seed(1.5)", errorMsg("argument 1 to 'seed' must be an int. got 'float'")},
+		{"choice([])", errorMsg("cannot choose from an empty array")},
+		{"sample([1, 2], 3)", errorMsg("cannot sample 3 elements from array of length 2")},
+		{"sample([1, 2], -1)", errorMsg("cannot sample -1 elements from array of length 2")},
+		{"shuffle(1)", errorMsg("argument to 'shuffle' not supported, got 'int'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestRandomPerInterpreter(t *testing.T) {
+	draw := func(env *object.Environment, input string) string {
+		p := parser.New(lexer.WithString(input, "testeval"))
+		return Eval(p.ParseProgram(), env, nil).String()
+	}
+
+	a, b := object.NewEnvironment(), object.NewEnvironment()
+	draw(a, "seed(42)")
+	draw(b, "seed(42)")
+	first := draw(a, "rand_int(1000000)")
+
+	// seeding and drawing from b doesn't change what a draws next
+	draw(b, "seed(1); rand_int(1000000)")
+	second := draw(a, "rand_int(1000000)")
+
+	c := object.NewEnvironment()
+	expected := draw(c, "seed(42); str([rand_int(1000000), rand_int(1000000)])")
+	if got := "[" + first + ", " + second + "]"; got != expected {
+		t.Errorf("interpreters share a random number generator. expected=%s, got=%s", expected, got)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let t = spawn(|a, b| a + b, 1, 2); t.recv()", "3"},
+		{"let t = spawn(|| 1); t.recv(); t.recv()", "nil"},
+		{"let c = chan(2); c.send(1); c.send(2); c.close(); let got = []; for v in c: push(got, v); got", "[1, 2]"},
+		{"let c = chan(); spawn(|| { for i in [1, 2, 3]: c.send(i); c.close() }); let s = 0; for v in c: s += v; s", "6"},
+		{"let c = chan(1); c.send('x'); [c.len(), c.recv(), c.len()]", "[1, x, 0]"},
+		{"let c = chan(); c.close(); c.recv()", "nil"},
+		{"let a = chan(); let b = chan(1); b.send(5); select([a, b])", "[1, 5]"},
+		{"let a = chan(1); select([[a, 7]]); a.recv()", "7"},
+		{"let a = chan(); select([a], 0)", "nil"},
+		{"let a = chan(); select([a], time.ms(5))", "nil"},
+		{"let a = chan(); a.close(); select([a])", "[0, nil]"},
+		{"let m = hashmap([]); let done = chan(); let work = |k| { for i in [1, 2, 3]: m[k * 10 + i] = i; done.send(k) }; for k in [1, 2, 3, 4]: spawn(work, k); for k in [1, 2, 3, 4]: done.recv(); len(m)", "12"},
+		{"let done = chan(4); for k in [1, 2, 3, 4]: spawn(|| { let x = k; done.send(x) }); let s = 0; for k in [1, 2, 3, 4]: s += done.recv(); s", "10"},
+		{"let t = spawn(|| 1 + 'a'); t.recv()", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"spawn(1)", errorMsg("first argument to 'spawn' must be a function. got 'int'")},
+		{"chan(-1)", errorMsg("channel size cannot be negative. got '-1'")},
+		{"let c = chan(1); c.close(); c.send(1)", errorMsg("cannot send on a closed channel")},
+		{"let c = chan(1); c.close(); c.close()", errorMsg("cannot close a closed channel")},
+		{"let c = chan(1); c.close(); select([[c, 1]])", errorMsg("cannot send on a closed channel")},
+		{"select([1])", errorMsg("select case 0 must be a channel or a [channel, value] pair. got 'int'")},
+		{"select([])", errorMsg("select needs a case or a timeout")},
+		{"select([], 'a')", errorMsg("second argument to 'select' must be a duration or int. got 'string'")},
+		{"let c = chan(); c.send = 1", errorMsg("cannot assign to constant 'send'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestConcurrencyStop(t *testing.T) {
+	// each waits on a channel nobody uses or runs forever until the program is stopped
+	inputs := []string{
+		"chan().recv()",
+		"chan().send(1)",
+		"select([chan()])",
+		"select([[chan(), 1]])",
+		"collect(chan())",
+		"for x in map(chan(), |x| x): x",
+		"collect(iter(|| 1))",
+	}
+
+	for _, input := range inputs {
+		stop := make(chan struct{})
+		done := make(chan object.Object)
+		go func() {
+			p := parser.New(lexer.WithString(input, "testeval"))
+			done <- Eval(p.ParseProgram(), object.NewEnvironment(), stop)
+		}()
+
+		// give it time to start waiting
+		time.Sleep(10 * time.Millisecond)
+		close(stop)
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Errorf("%q was not stopped", input)
+		}
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	count := "let count = |n| { let i = 0; while i < n { yield i; i += 1 } }; "
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{count + "let got = []; for x in count(3): push(got, x); got", "[0, 1, 2]"},
+		{count + "let g = count(2); [g.next(), g.next(), g.next(), g.next()]", "[0, 1, nil, nil]"},
+		{count + "map(count(4), |x| x * x)", "[0, 1, 4, 9]"},
+		{count + "filter(count(6), |x| x % 2 == 0)", "[0, 2, 4]"},
+		{count + "reduce(count(5), |a, b| a + b)", "10"},
+		{count + "typeof(count(1))", "iterator"},
+		{"let letters = || { for c in 'abc': yield upper(c) }; join(map(letters(), |c| c), '')", "ABC"},
+		{"let nat = || { let i = 0; while true { yield i; i += 1 } }; let n = nat(); n.next(); let x = n.next(); n.close(); [x, n.next()]", "[1, nil]"},
+		{"let g = |a, b = 10| { yield a; yield b }; let it = g(1); [it.next(), it.next()]", "[1, 10]"},
+		{"let g = || { yield 1; ret 5; yield 2 }; map(g(), |x| x)", "[1]"},
+		{"let g = || { yield 1; yield 2 }; let first = nil; for x in g() { first = x; ret x }", "1"},
+		{"let outer = || { let inner = || { yield 1; yield 2 }; for x in inner(): yield x * 10 }; map(outer(), |x| x)", "[10, 20]"},
+		{"let seen = []; let g = || { push(seen, 'started'); yield 1 }; let it = g(); len(seen)", "0"},
+		{"let f = || { let i = 0; while i < 10 { if i == 3: ret i; i += 1 } }; f()", "3"},
+		{"let g = || { yield 1; 1 + 'a' }; map(g(), |x| x)", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"let g = || { yield 1 + 'a' }; let it = g(); it.next()", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"let g = || { yield 1 }; let it = g(); it.next = 1", errorMsg("cannot assign to constant 'next'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
+func TestGeneratorsStop(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// leave generators waiting at a yield then stop the program
+	stop := make(chan struct{})
+	l := lexer.WithString("let nat = || { let i = 0; while true { yield i; i += 1 } }; let gens = map([1, 2, 3], |_| nat()); each(gens, |g| g.next()); gens", "testeval")
+	p := parser.New(l)
+	evaluated := Eval(p.ParseProgram(), object.NewEnvironment(), stop)
+	if arr, ok := evaluated.(*object.Array); !ok || len(arr.Elements) != 3 {
+		t.Fatalf("wrong result. got=%T (%+v)", evaluated, evaluated)
+	}
+	if runtime.NumGoroutine() < before+3 {
+		t.Fatalf("generators are not running. got %d goroutines, had %d", runtime.NumGoroutine(), before)
+	}
+
+	close(stop)
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("generators were not cleaned up. got %d goroutines, had %d", n, before)
+	}
+}
+
+func TestIterators(t *testing.T) {
+	counter := "class Counter { let n = 0; let max = 3; let next = || { if self.n < self.max { self.n += 1; ret self.n } } }; "
+	nat := "let nat = || { let i = 0; while true { yield i; i += 1 } }; "
+	// a closure object shaped like examples/loops.dusk
+	closure := "let counter = |n| { let i = 0; let next = || { if i < n { i += 1; ret i } }; ret || counter }; "
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{counter + "let got = []; for x in Counter!: push(got, x); got", "[1, 2, 3]"},
+		{counter + "map(Counter!, |x| x * 10)", "[10, 20, 30]"},
+		{counter + "sum(Counter!)", "6"},
+		{counter + "len(Counter!)", "3"},
+		{counter + "collect(take(Counter!, 2))", "[1, 2]"},
+		{"let n = 0; let f = || { n += 1; if n < 3: n }; collect(iter(f))", "[1, 2]"},
+		{closure + "let got = []; for x in counter(3): push(got, x); got", "[1, 2, 3]"},
+		{closure + "collect(iter(counter(3)))", "[1, 2, 3]"},
+		{closure + "map(counter(3), |x| x * 10)", "[10, 20, 30]"},
+		{closure + "let c = counter(3); c.next(); collect(c)", "[2, 3]"},
+		{"let it = iter([1, 2]); [it.next(), it.next(), it.next()]", "[1, 2, nil]"},
+		{"collect(iter('héllo'))", "[h, é, l, l, o]"},
+		{"collect(hashmap([['a', 1]]))", "[[a, 1]]"},
+		{"let c = chan(2); c.send(1); c.send(2); c.close(); collect(c)", "[1, 2]"},
+		{nat + "collect(take(nat(), 4))", "[0, 1, 2, 3]"},
+		{nat + "collect(take(skip(nat(), 5), 2))", "[5, 6]"},
+		{nat + "let it = nat(); collect(take(it, 2)); it.next()", "2"},
+		{nat + "let it = take(nat(), 3); it.close(); it.next()", "nil"},
+		{"collect(skip([1, 2, 3], 5))", "[]"},
+		{"collect(take('abc', 0))", "[]"},
+		{"collect(chain([1, 2], 'ab', range(2)))", "[1, 2, a, b, 0, 1]"},
+		{"collect(chain())", "[]"},
+		{"collect(range(4))", "[0, 1, 2, 3]"},
+		{"collect(range(2, 5))", "[2, 3, 4]"},
+		{"collect(range(5, 0, -2))", "[5, 3, 1]"},
+		{"collect(range(3, 3))", "[]"},
+		{"collect(range(9223372036854775800, 9223372036854775807, 5))", "[9223372036854775800, 9223372036854775805]"},
+		{"collect(range(-9223372036854775800, -9223372036854775807 - 1, -5))", "[-9223372036854775800, -9223372036854775805]"},
+		{"collect(range(-9223372036854775807 - 1, 9223372036854775807, 9223372036854775807))", "[-9223372036854775808, -1, 9223372036854775806]"},
+		{"let s = 0; for i in range(1, 101): s += i; s", "5050"},
+		{"len(range(10))", "10"},
+		{"map(take(range(100), 3), |x| x * x)", "[0, 1, 4]"},
+		{"let seen = []; let g = || { for x in [1, 2, 3] { push(seen, x); yield x } }; collect(take(g(), 1)); seen", "[1]"},
+		{"typeof(range(1))", "iterator"},
+		{counter + "typeof(iter(Counter!))", "iterator"},
+		{"let g = || { yield 1; 1 + 'a' }; collect(take(g(), 5))", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"class Bad { let next = || 1 + 'a' }; for x in Bad!: x", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"class Bad { let next = || 1 + 'a' }; len(Bad!)", errorMsg("cannot apply operator '+' for type 'int' and 'string'")},
+		{"take([1], -1)", errorMsg("second argument to 'take' cannot be negative. got '-1'")},
+		{"skip(1, 1)", errorMsg("argument to 'skip' not supported, got 'int'")},
+		{"chain([1], 2)", errorMsg("argument to 'chain' not supported, got 'int'")},
+		{"collect(1)", errorMsg("argument to 'collect' not supported, got 'int'")},
+		{"range(1, 2, 0)", errorMsg("range step cannot be 0")},
+		{"range('a')", errorMsg("argument 1 to 'range' must be an int. got 'string'")},
+		{"range()", errorMsg("wrong number of arguments. got '0', expected between '1' and '3'")},
+		{"for x in 1: x", errorMsg("cannot iterate over type 'int'")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, tt.expected)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.WithString(input, "testeval")
 	p := parser.New(l)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
 
-	return Eval(program, env)
+	return Eval(program, env, nil)
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -623,3 +2004,34 @@ func testNilObject(t *testing.T, obj object.Object) bool {
 	}
 	return true
 }
+
+// errorMsg is an expected error message in tests that also expect strings
+type errorMsg string
+
+// testExpected evaluates input and checks the result prints as the expected string
+// or is an error with the expected message and a position
+func testExpected(t *testing.T, input string, expected interface{}) {
+	t.Helper()
+	evaluated := testEval(input)
+
+	switch expected := expected.(type) {
+	case string:
+		if _, ok := evaluated.(*object.Error); ok || evaluated == nil || evaluated.String() != expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%T (%+v)", input, expected, evaluated, evaluated)
+		}
+	case errorMsg:
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			return
+		}
+		if errObj.Message != string(expected) {
+			t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+		}
+		if errObj.Pos.Line == 0 {
+			t.Errorf("error has no position. %q", errObj.Message)
+		}
+	default:
+		t.Fatalf("unsupported expected type %T for %q", expected, input)
+	}
+}
@@ -0,0 +1,274 @@
+package eval
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func fsModule() *object.Module {
+	return newModule("fs", map[string]object.Object{
+		"open":     fsFn(fsOpen),
+		"append":   fsFn(fsAppend),
+		"exists":   fsFn(fsExists),
+		"stat":     fsFn(fsStat),
+		"list_dir": fsFn(fsListDir),
+		"mkdir":    fsFn(fsMkdir),
+		"remove":   fsFn(fsRemove),
+		"rename":   fsFn(fsRename),
+		"glob":     fsFn(fsGlob),
+	})
+}
+
+// fsFn makes a builtin that errors unless AllowOS is set
+func fsFn(fn object.BuiltinFunction) *object.Builtin {
+	return restrictedFn("file system", fn)
+}
+
+// fileModes are the modes a file can be opened with
+var fileModes = map[string]int{
+	"r":  os.O_RDONLY,
+	"w":  os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a":  os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	"r+": os.O_RDWR,
+	"w+": os.O_RDWR | os.O_CREATE | os.O_TRUNC,
+	"a+": os.O_RDWR | os.O_CREATE | os.O_APPEND,
+}
+
+// fileHandle is the go side of a dusk file
+type fileHandle struct {
+	file   *os.File
+	reader *bufio.Reader
+}
+
+// osError is a dusk error for an error from the os
+func osError(err error) object.Object {
+	return newError(token.Position{}, "%s", err)
+}
+
+// fsOpen opens a file with a mode like 'r', 'w' or 'a'. the default is 'r'
+func fsOpen(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("open", args, 1, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	mode := "r"
+	if len(strs) == 2 {
+		mode = strs[1]
+	}
+	flag, ok := fileModes[mode]
+	if !ok {
+		return newError(token.Position{}, "unknown file mode '%s'. expected one of r, w, a, r+, w+ or a+", mode)
+	}
+
+	f, openErr := os.OpenFile(strs[0], flag, 0666)
+	if openErr != nil {
+		return osError(openErr)
+	}
+
+	h := &fileHandle{file: f, reader: bufio.NewReader(f)}
+	file := &object.File{Path: strs[0], Env: object.NewEnvironment()}
+	file.Env.SetConst("read_line", &object.Builtin{Fn: h.readLine})
+	file.Env.SetConst("read", &object.Builtin{Fn: h.read})
+	file.Env.SetConst("write", &object.Builtin{Fn: h.write})
+	file.Env.SetConst("close", &object.Builtin{Fn: h.close})
+	file.Env.SetConst("path", &object.String{Value: strs[0]})
+	return file
+}
+
+// readLine reads the next line without the line ending. nil at the end of the file
+func (h *fileHandle) readLine(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+
+	line, err := h.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return osError(err)
+	}
+	if err == io.EOF && line == "" {
+		return ConstNil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return &object.String{Value: strings.TrimSuffix(line, "\r")}
+}
+
+// read reads the rest of the file or up to n bytes. nil at the end of the file
+func (h *fileHandle) read(call object.Caller, args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 1'", len(args))
+	}
+
+	if len(args) == 0 {
+		b, err := ioutil.ReadAll(h.reader)
+		if err != nil {
+			return osError(err)
+		}
+		return &object.String{Value: string(b)}
+	}
+
+	n, errObj := intArg("read", args, 0, 0)
+	if errObj != nil {
+		return errObj
+	}
+	if n < 0 {
+		return newError(token.Position{}, "cannot read a negative number of bytes. got '%d'", n)
+	}
+	b := make([]byte, n)
+	read, err := io.ReadFull(h.reader, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return osError(err)
+	}
+	if read == 0 && n > 0 {
+		return ConstNil
+	}
+	return &object.String{Value: string(b[:read])}
+}
+
+// write writes a string and returns the number of bytes written
+func (h *fileHandle) write(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("write", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	n, err := h.file.WriteString(strs[0])
+	if err != nil {
+		return osError(err)
+	}
+	return &object.Integer{Value: int64(n)}
+}
+
+func (h *fileHandle) close(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	if err := h.file.Close(); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+// fsAppend adds a string to the end of a file, creating it if needed
+func fsAppend(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("append", args, 2, 2, 2)
+	if errObj != nil {
+		return errObj
+	}
+
+	f, err := os.OpenFile(strs[0], fileModes["a"], 0666)
+	if err != nil {
+		return osError(err)
+	}
+	if _, err := f.WriteString(strs[1]); err != nil {
+		f.Close()
+		return osError(err)
+	}
+	if err := f.Close(); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+func fsExists(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("exists", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	_, err := os.Stat(strs[0])
+	if err != nil && !os.IsNotExist(err) {
+		return osError(err)
+	}
+	return boolToBoolean(err == nil)
+}
+
+// fsStat is a map of the name, size, whether it's a dir, mode and the modified time in unix seconds
+func fsStat(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("stat", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	info, err := os.Stat(strs[0])
+	if err != nil {
+		return osError(err)
+	}
+
+	m := object.NewMap()
+	m.Set(&object.String{Value: "name"}, &object.String{Value: info.Name()})
+	m.Set(&object.String{Value: "size"}, &object.Integer{Value: info.Size()})
+	m.Set(&object.String{Value: "dir"}, boolToBoolean(info.IsDir()))
+	m.Set(&object.String{Value: "mode"}, &object.String{Value: info.Mode().String()})
+	m.Set(&object.String{Value: "modified"}, &object.Integer{Value: info.ModTime().Unix()})
+	return m
+}
+
+// fsListDir is the sorted names of the entries in a directory
+func fsListDir(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("list_dir", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	infos, err := ioutil.ReadDir(strs[0])
+	if err != nil {
+		return osError(err)
+	}
+
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return stringArray(names)
+}
+
+// fsMkdir makes a directory and any missing parents
+func fsMkdir(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("mkdir", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	if err := os.MkdirAll(strs[0], 0777); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+// fsRemove removes a file or an empty directory
+func fsRemove(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("remove", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	if err := os.Remove(strs[0]); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+func fsRename(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("rename", args, 2, 2, 2)
+	if errObj != nil {
+		return errObj
+	}
+	if err := os.Rename(strs[0], strs[1]); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+// fsGlob is the sorted paths matching a pattern such as '*.dusk'
+func fsGlob(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("glob", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	matches, err := filepath.Glob(strs[0])
+	if err != nil {
+		return newError(token.Position{}, "invalid glob pattern '%s'. %s", strs[0], err)
+	}
+	return stringArray(matches)
+}
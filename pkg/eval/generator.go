@@ -0,0 +1,145 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"runtime"
+	"sync"
+)
+
+// generator runs the body of a function that yields on its own goroutine.
+// the body waits at each yield until the next value is asked for
+type generator struct {
+	mu       sync.Mutex // one next at a time
+	started  bool
+	finished bool
+
+	body *ast.BlockStatement
+	env  *object.Environment
+	stop <-chan struct{}
+
+	values chan object.Object // yielded values
+	resume chan struct{}      // lets the body carry on after a yield
+	done   chan struct{}      // closed when the body is done
+	quit   chan struct{}      // closed to make the body give up at its next yield
+	once   sync.Once
+
+	err    object.Object // the error that ended the body
+	closed object.Object // returned by yield to unwind the body after quit
+}
+
+// the generator is bound to the name yield in the function's environment.
+// yield is a keyword so scripts can't see it
+func (g *generator) Type() object.Type                          { return object.IteratorType }
+func (g *generator) String() string                             { return "generator" }
+func (g *generator) CanApply(op token.Type, t object.Type) bool { return false }
+
+// newGenerator makes an iterator over the values f yields. env has the args bound
+func newGenerator(f *object.Function, env *object.Environment, stop <-chan struct{}) *object.Iterator {
+	g := &generator{
+		body:   f.Body,
+		env:    env,
+		stop:   stop,
+		values: make(chan object.Object),
+		resume: make(chan struct{}),
+		done:   make(chan struct{}),
+		quit:   make(chan struct{}),
+		closed: newError(token.Position{}, "generator closed"),
+	}
+	env.Set("yield", g)
+
+	it := newIterator(g.next, g.close)
+	// the body can't reach the iterator so it can be collected while the body waits at a yield
+	runtime.SetFinalizer(it, func(*object.Iterator) { g.abandon() })
+	return it
+}
+
+// next runs the body to its next yield. an error in the body is returned as the last value
+func (g *generator) next() (object.Object, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.finished {
+		return nil, false
+	}
+	if !g.started {
+		g.started = true
+		go g.run()
+	} else {
+		select {
+		case g.resume <- struct{}{}:
+		case <-g.done:
+		}
+	}
+
+	select {
+	case v := <-g.values:
+		return v, true
+	case <-g.done:
+		g.finished = true
+		if g.err != nil {
+			return g.err, true
+		}
+		return nil, false
+	}
+}
+
+func (g *generator) run() {
+	defer close(g.done)
+	result := Eval(g.body, g.env, g.stop)
+	if isError(result) && result != g.closed {
+		g.err = result
+	}
+}
+
+// yield hands v to next and waits to be resumed.
+// it returns an error to unwind the body if the generator is closed or the program is stopped
+func (g *generator) yield(v object.Object) object.Object {
+	select {
+	case g.values <- v:
+	case <-g.quit:
+		return g.closed
+	case <-g.stop:
+		return g.closed
+	}
+
+	select {
+	case <-g.resume:
+		return ConstNil
+	case <-g.quit:
+		return g.closed
+	case <-g.stop:
+		return g.closed
+	}
+}
+
+// abandon tells the body to stop at its next yield
+func (g *generator) abandon() {
+	g.once.Do(func() { close(g.quit) })
+}
+
+// close stops the body and waits for it to finish
+func (g *generator) close() {
+	g.abandon()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.started && !g.finished {
+		<-g.done
+	}
+	g.finished = true
+}
+
+func evalYieldExpr(node *ast.YieldExpression, env *object.Environment, stop <-chan struct{}) object.Object {
+	val := Eval(node.Value, env, stop)
+	if isError(val) {
+		return val
+	}
+
+	g, ok := env.Get("yield")
+	if !ok {
+		return newError(node.Token.Pos, "yield can only be used inside a function")
+	}
+	return g.(*generator).yield(val)
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+// iterate calls fn with each item of an iterable object in order.
+// a non nil result from fn stops the iteration and is returned, which is how
+// errors and return values are passed back up from a loop body.
+// arrays yield their elements, strings yield each character and maps yield [key, value] pairs.
+// channels yield what they receive until they're closed. iterators and objects with a next
+// method yield until they run out
+func iterate(pos token.Position, iterable object.Object, env *object.Environment, stop <-chan struct{}, fn func(object.Object) object.Object) object.Object {
+	var items []object.Object
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		items = iterable.Elements
+	case *object.String:
+		for _, r := range iterable.Value {
+			items = append(items, &object.String{Value: string(r)})
+		}
+	case *object.Map:
+		for _, pair := range iterable.Pairs() {
+			items = append(items, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+		}
+	case *object.Channel:
+		return iterateChannel(iterable, stop, fn)
+	case *object.Iterator:
+		return iterateIterator(iterable, stop, fn)
+	default:
+		// objects with a next method are iterators
+		if next, ok := specialMethod(iterable, "next"); ok {
+			call := &caller{t: token.Token{Pos: pos}, env: env, stop: stop}
+			return iterateIterator(callIterator(call, next), stop, fn)
+		}
+		return newError(pos, "cannot iterate over type '%s'", iterable.Type())
+	}
+
+	for _, item := range items {
+		select {
+		case <-stop:
+			return ConstNil
+		default:
+		}
+
+		if result := fn(item); result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// isIterable is true for objects iterate can go through
+func isIterable(o object.Object) bool {
+	switch o.(type) {
+	case *object.Array, *object.String, *object.Map, *object.Channel:
+		return true
+	default:
+		return isIterator(o)
+	}
+}
+
+func iterateChannel(c *object.Channel, stop <-chan struct{}, fn func(object.Object) object.Object) object.Object {
+	for {
+		select {
+		case <-stop:
+			return ConstNil
+		case item, ok := <-c.Value:
+			if !ok {
+				return nil
+			}
+			if result := fn(item); result != nil {
+				return result
+			}
+		}
+	}
+}
+
+func iterateIterator(it *object.Iterator, stop <-chan struct{}, fn func(object.Object) object.Object) object.Object {
+	for {
+		select {
+		case <-stop:
+			return ConstNil
+		default:
+		}
+
+		item, ok := it.Next()
+		if !ok {
+			return nil
+		}
+		if isError(item) {
+			return item
+		}
+		if result := fn(item); result != nil {
+			return result
+		}
+	}
+}
+
+// newIterator makes an Iterator with next and close members.
+// next is nil when there are no more values
+func newIterator(next func() (object.Object, bool), close func()) *object.Iterator {
+	it := &object.Iterator{Next: next, Close: close, Env: object.NewEnvironment()}
+	it.Env.SetConst("next", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+		}
+		if v, ok := next(); ok {
+			return v
+		}
+		return ConstNil
+	}})
+	it.Env.SetConst("close", &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+		}
+		close()
+		return ConstNil
+	}})
+	return it
+}
@@ -0,0 +1,255 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+// callIterator makes an iterator that calls fn for each value until it returns nil.
+// this is how objects with a next method and iter(fn) work
+func callIterator(call object.Caller, fn object.Object) *object.Iterator {
+	done := false
+	return newIterator(func() (object.Object, bool) {
+		if done {
+			return nil, false
+		}
+		v := call.Call(fn)
+		if v == nil || v.Type() == object.NilType {
+			done = true
+			return nil, false
+		}
+		if isError(v) {
+			done = true
+		}
+		return v, true
+	}, func() { done = true })
+}
+
+// isIterator is true for iterators and objects with a next method
+func isIterator(o object.Object) bool {
+	if _, ok := o.(*object.Iterator); ok {
+		return true
+	}
+	_, ok := specialMethod(o, "next")
+	return ok
+}
+
+// toIterator makes a lazy iterator over arrays, strings, maps, channels,
+// iterators and objects with a next method for the builtin called name
+func toIterator(call object.Caller, name string, o object.Object) (*object.Iterator, object.Object) {
+	switch o := o.(type) {
+	case *object.Iterator:
+		return o, nil
+	case *object.Array:
+		i := 0
+		return newIterator(func() (object.Object, bool) {
+			if i >= len(o.Elements) {
+				return nil, false
+			}
+			i++
+			return o.Elements[i-1], true
+		}, func() { i = len(o.Elements) }), nil
+	case *object.String:
+		runes := []rune(o.Value)
+		i := 0
+		return newIterator(func() (object.Object, bool) {
+			if i >= len(runes) {
+				return nil, false
+			}
+			i++
+			return &object.String{Value: string(runes[i-1])}, true
+		}, func() { i = len(runes) }), nil
+	case *object.Map:
+		pairs := o.Pairs()
+		i := 0
+		return newIterator(func() (object.Object, bool) {
+			if i >= len(pairs) {
+				return nil, false
+			}
+			i++
+			return &object.Array{Elements: []object.Object{pairs[i-1].Key, pairs[i-1].Value}}, true
+		}, func() { i = len(pairs) }), nil
+	case *object.Channel:
+		stop := call.Stop()
+		return newIterator(func() (object.Object, bool) {
+			select {
+			case <-stop:
+				return nil, false
+			case v, ok := <-o.Value:
+				return v, ok
+			}
+		}, func() {}), nil
+	}
+
+	if next, ok := specialMethod(o, "next"); ok {
+		return callIterator(call, next), nil
+	}
+	return nil, newError(token.Position{}, "argument to '%s' not supported, got '%s'", name, o.Type())
+}
+
+// iter makes an iterator from an iterable. a function without a next method is called until it returns nil
+func iter(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	switch args[0].Type() {
+	case object.FunctionType, object.BuiltinType:
+		if !isIterator(args[0]) {
+			return callIterator(call, args[0])
+		}
+	}
+	it, err := toIterator(call, "iter", args[0])
+	if err != nil {
+		return err
+	}
+	return it
+}
+
+// countArgs checks the args of take and skip
+func countArgs(call object.Caller, name string, args []object.Object) (*object.Iterator, int64, object.Object) {
+	if len(args) != 2 {
+		return nil, 0, newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	it, err := toIterator(call, name, args[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := intArg(name, args, 1, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n < 0 {
+		return nil, 0, newError(token.Position{}, "second argument to '%s' cannot be negative. got '%d'", name, n)
+	}
+	return it, n, nil
+}
+
+// take is an iterator over the first n values
+func take(call object.Caller, args ...object.Object) object.Object {
+	it, n, err := countArgs(call, "take", args)
+	if err != nil {
+		return err
+	}
+	return newIterator(func() (object.Object, bool) {
+		if n <= 0 {
+			return nil, false
+		}
+		n--
+		return it.Next()
+	}, it.Close)
+}
+
+// skip is an iterator over the values after the first n
+func skip(call object.Caller, args ...object.Object) object.Object {
+	it, n, err := countArgs(call, "skip", args)
+	if err != nil {
+		return err
+	}
+	return newIterator(func() (object.Object, bool) {
+		for ; n > 0; n-- {
+			v, ok := it.Next()
+			if !ok || isError(v) {
+				return v, ok
+			}
+		}
+		return it.Next()
+	}, it.Close)
+}
+
+// chain is an iterator over the values of each arg one after another
+func chain(call object.Caller, args ...object.Object) object.Object {
+	its := make([]*object.Iterator, len(args))
+	for i, arg := range args {
+		it, err := toIterator(call, "chain", arg)
+		if err != nil {
+			return err
+		}
+		its[i] = it
+	}
+
+	return newIterator(func() (object.Object, bool) {
+		for len(its) > 0 {
+			if v, ok := its[0].Next(); ok {
+				return v, ok
+			}
+			its = its[1:]
+		}
+		return nil, false
+	}, func() {
+		for _, it := range its {
+			it.Close()
+		}
+		its = nil
+	})
+}
+
+// collect is an array of the rest of the values
+func collect(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	it, err := toIterator(call, "collect", args[0])
+	if err != nil {
+		return err
+	}
+
+	items := []object.Object{}
+	for {
+		v, ok := it.Next()
+		select {
+		case <-call.Stop():
+			return ConstNil
+		default:
+		}
+		if !ok {
+			return &object.Array{Elements: items}
+		}
+		if isError(v) {
+			return v
+		}
+		items = append(items, v)
+	}
+}
+
+// rangeIter is an iterator over the ints from start up to but not including end.
+// range(end) starts at 0 and the optional step can be negative
+func rangeIter(call object.Caller, args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected between '1' and '3'", len(args))
+	}
+
+	bounds := []int64{0, 0, 1}
+	for i := range args {
+		n, err := intArg("range", args, i, 0)
+		if err != nil {
+			return err
+		}
+		bounds[i] = n
+	}
+	if len(args) == 1 {
+		bounds[0], bounds[1] = 0, bounds[0]
+	}
+
+	i, end, step := bounds[0], bounds[1], bounds[2]
+	if step == 0 {
+		return newError(token.Position{}, "range step cannot be 0")
+	}
+	return newIterator(func() (object.Object, bool) {
+		if (step > 0 && i >= end) || (step < 0 && i <= end) {
+			return nil, false
+		}
+		v := i
+
+		// the last step could overflow past end. the distance to end always fits a uint64
+		left, size := uint64(end)-uint64(i), uint64(step)
+		if step < 0 {
+			left, size = -left, -size
+		}
+		if left <= size {
+			i = end
+		} else {
+			i += step
+		}
+		return &object.Integer{Value: v}, true
+	}, func() { i = end })
+}
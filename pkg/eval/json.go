@@ -0,0 +1,247 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonParse decodes a json string. objects become maps that keep the order of their keys
+func jsonParse(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("json_parse", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	src := strs[0]
+
+	d := json.NewDecoder(strings.NewReader(src))
+	d.UseNumber()
+
+	val, decodeErr := decodeJSON(d)
+	if decodeErr == nil {
+		end := d.InputOffset()
+		if _, extra := d.Token(); extra != io.EOF {
+			rest := src[end:]
+			end += int64(len(rest) - len(strings.TrimLeft(rest, " \t\r\n")))
+			return jsonError(src, end, "unexpected data after value")
+		}
+		return val
+	}
+
+	offset := d.InputOffset()
+	msg := decodeErr.Error()
+	if syntax, ok := decodeErr.(*json.SyntaxError); ok {
+		offset = syntax.Offset
+		// the offset of an invalid character is just after it
+		if strings.HasPrefix(msg, "invalid character") {
+			offset--
+		}
+	}
+	if decodeErr == io.EOF || decodeErr == io.ErrUnexpectedEOF || strings.HasPrefix(msg, "unexpected end") {
+		msg = "unexpected end of input"
+	}
+	return jsonError(src, offset, msg)
+}
+
+// jsonError is a parse error with the line and column of the offset in src
+func jsonError(src string, offset int64, msg string) object.Object {
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+	for offset > 0 && offset < int64(len(src)) && !utf8.RuneStart(src[offset]) {
+		offset--
+	}
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return newError(token.Position{}, "invalid json at line %d, column %d. %s", line, column, msg)
+}
+
+func decodeJSON(d *json.Decoder) (object.Object, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '[':
+			elems := []object.Object{}
+			for d.More() {
+				elem, err := decodeJSON(d)
+				if err != nil {
+					return nil, err
+				}
+				elems = append(elems, elem)
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return &object.Array{Elements: elems}, nil
+		case '{':
+			m := object.NewMap()
+			for d.More() {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := decodeJSON(d)
+				if err != nil {
+					return nil, err
+				}
+				m.Set(&object.String{Value: key.(string)}, val)
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return m, nil
+		}
+	case string:
+		return &object.String{Value: tok}, nil
+	case bool:
+		return boolToBoolean(tok), nil
+	case nil:
+		return ConstNil, nil
+	case json.Number:
+		return jsonNumber(tok)
+	}
+	return nil, &json.SyntaxError{Offset: d.InputOffset()}
+}
+
+// jsonNumber is an int for integral numbers and a float otherwise
+func jsonNumber(n json.Number) (object.Object, error) {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if v, ok := new(big.Int).SetString(s, 10); ok {
+			return intFromBig(v), nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &object.Float{Value: f}, nil
+}
+
+// jsonStringify encodes a value as json. the optional indent is a number of spaces or a string
+func jsonStringify(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, args[0], map[object.Object]bool{}); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return &object.String{Value: buf.String()}
+	}
+
+	var indent string
+	switch arg := args[1].(type) {
+	case *object.Integer:
+		if arg.Value < 0 {
+			return newError(token.Position{}, "json indent cannot be negative. got '%d'", arg.Value)
+		}
+		indent = strings.Repeat(" ", int(arg.Value))
+	case *object.String:
+		indent = arg.Value
+	default:
+		return newError(token.Position{}, "second argument to 'json_stringify' must be an int or string. got '%s'", arg.Type())
+	}
+
+	var out bytes.Buffer
+	json.Indent(&out, buf.Bytes(), "", indent)
+	return &object.String{Value: out.String()}
+}
+
+// encodeJSON writes o to buf. seen holds the arrays and maps being written to catch cycles
+func encodeJSON(buf *bytes.Buffer, o object.Object, seen map[object.Object]bool) object.Object {
+	switch o := o.(type) {
+	case *object.Nil:
+		buf.WriteString("null")
+	case *object.Boolean:
+		buf.WriteString(strconv.FormatBool(o.Value))
+	case *object.Integer:
+		buf.WriteString(strconv.FormatInt(o.Value, 10))
+	case *object.BigInt:
+		buf.WriteString(o.Value.String())
+	case *object.Float, *object.Rational:
+		f := promote(o, object.FloatType).(*object.Float).Value
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return newError(token.Position{}, "cannot convert '%s' to json", o)
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		// whole floats keep a fraction so they parse back as floats
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		buf.WriteString(s)
+	case *object.String:
+		writeJSONString(buf, o.Value)
+	case *object.Array:
+		if seen[o] {
+			return newError(token.Position{}, "cannot convert array that contains itself to json")
+		}
+		seen[o] = true
+		defer delete(seen, o)
+
+		buf.WriteByte('[')
+		for i, e := range o.Elements {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, e, seen); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *object.Map:
+		if seen[o] {
+			return newError(token.Position{}, "cannot convert map that contains itself to json")
+		}
+		seen[o] = true
+		defer delete(seen, o)
+
+		// json keys are strings so keys like 1 and '1' would write the same key twice
+		keys := map[string]bool{}
+		buf.WriteByte('{')
+		for i, pair := range o.Pairs() {
+			key := pair.Key.String()
+			if keys[key] {
+				return newError(token.Position{}, "cannot convert map with more than one key written as '%s' to json", key)
+			}
+			keys[key] = true
+
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, pair.Value, seen); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return newError(token.Position{}, "cannot convert type '%s' to json", o.Type())
+	}
+	return nil
+}
+
+// writeJSONString writes s as a quoted json string without escaping html
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var out bytes.Buffer
+	e := json.NewEncoder(&out)
+	e.SetEscapeHTML(false)
+	e.Encode(s)
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+// evalMatchExpr evaluates the body of the first arm whose pattern and guard match the value
+func evalMatchExpr(node *ast.MatchExpression, env *object.Environment, stop <-chan struct{}) object.Object {
+	val := Eval(node.Value, env, stop)
+	if isError(val) {
+		return val
+	}
+
+	for _, arm := range node.Arms {
+		// each arm gets its own scope for the names it binds
+		armEnv := object.NewChildEnvironment(env)
+
+		matched, err := matchPattern(arm.Pattern, val, armEnv, stop)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv, stop)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		result := Eval(arm.Body, armEnv, stop)
+		if result == nil {
+			return ConstNil
+		}
+		return result
+	}
+
+	return newError(node.Token.Pos, "non-exhaustive match. no arm matched value '%s'", val)
+}
+
+// matchPattern checks if val has the shape of pattern, binding names in env as it goes
+func matchPattern(pattern ast.Expression, val object.Object, env *object.Environment, stop <-chan struct{}) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		// '_' matches anything without binding it
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, val)
+		}
+		return true, nil
+	case *ast.ArrayPattern:
+		array, ok := val.(*object.Array)
+		if !ok {
+			return false, nil
+		}
+
+		n := len(pattern.Elements)
+		if len(array.Elements) < n || (!pattern.HasRest && len(array.Elements) != n) {
+			return false, nil
+		}
+
+		for i, p := range pattern.Elements {
+			if matched, err := matchPattern(p, array.Elements[i], env, stop); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(array.Elements)-n)
+			copy(rest, array.Elements[n:])
+			env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+		}
+		return true, nil
+	case *ast.RangePattern:
+		// inclusive of both ends
+		below, ok, err := compareLiteral(token.Less, pattern.Token.Pos, val, pattern.Low, env, stop)
+		if !ok || below {
+			return false, err
+		}
+		above, ok, err := compareLiteral(token.Greater, pattern.Token.Pos, val, pattern.High, env, stop)
+		return ok && !above, err
+	default:
+		equal, _, err := compareLiteral(token.Equal, token.Position{}, val, pattern, env, stop)
+		return equal, err
+	}
+}
+
+// compareLiteral applies the comparison op to val and the literal.
+// ok is false if val cannot be compared with the literal or there was an error
+func compareLiteral(op token.Type, pos token.Position, val object.Object, literal ast.Expression, env *object.Environment, stop <-chan struct{}) (result, ok bool, err object.Object) {
+	lit := Eval(literal, env, stop)
+	if isError(lit) {
+		return false, false, lit
+	}
+
+	if op != token.Equal && !val.CanApply(op, lit.Type()) {
+		if _, overloaded := specialMethod(val, operatorMethods[op].method); !overloaded {
+			return false, false, nil
+		}
+	}
+
+	cmp := evalInfixExpr(token.Token{Type: op, Literal: op.String(), Pos: pos}, val, lit, env, stop)
+	if isError(cmp) {
+		return false, false, cmp
+	}
+	return isTruthy(cmp), true, nil
+}
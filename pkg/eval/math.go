@@ -0,0 +1,275 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// newModule makes a namespace whose members cannot be reassigned
+func newModule(name string, members map[string]object.Object) *object.Module {
+	env := object.NewEnvironment()
+	for k, v := range members {
+		env.SetConst(k, v)
+	}
+	return &object.Module{Name: name, Env: env}
+}
+
+func mathModule() *object.Module {
+	return newModule("math", map[string]object.Object{
+		"pi":  &object.Float{Value: math.Pi},
+		"e":   &object.Float{Value: math.E},
+		"inf": &object.Float{Value: math.Inf(1)},
+		"nan": &object.Float{Value: math.NaN()},
+
+		"sqrt":   floatFn("sqrt", math.Sqrt),
+		"sin":    floatFn("sin", math.Sin),
+		"cos":    floatFn("cos", math.Cos),
+		"tan":    floatFn("tan", math.Tan),
+		"asin":   floatFn("asin", math.Asin),
+		"acos":   floatFn("acos", math.Acos),
+		"atan":   floatFn("atan", math.Atan),
+		"exp":    floatFn("exp", math.Exp),
+		"log2":   floatFn("log2", math.Log2),
+		"log10":  floatFn("log10", math.Log10),
+		"floor":  roundFn("floor", math.Floor),
+		"ceil":   roundFn("ceil", math.Ceil),
+		"round":  roundFn("round", math.Round),
+		"atan2":  &object.Builtin{Fn: atan2},
+		"log":    &object.Builtin{Fn: logFn},
+		"abs":    &object.Builtin{Fn: abs},
+		"is_nan": &object.Builtin{Fn: isNaN},
+		"is_inf": &object.Builtin{Fn: isInf},
+	})
+}
+
+// floatArg converts any number to a float64
+func floatArg(name string, o object.Object) (float64, object.Object) {
+	if numericRank(o) == 0 {
+		return 0, newError(token.Position{}, "argument to '%s' must be a number. got '%s'", name, o.Type())
+	}
+	return promote(o, object.FloatType).(*object.Float).Value, nil
+}
+
+// floatFn makes a math builtin that applies f to a number promoted to a float
+func floatFn(name string, f func(float64) float64) *object.Builtin {
+	return &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+		}
+		x, err := floatArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: f(x)}
+	}}
+}
+
+// roundFn makes a rounding builtin. ints are already whole so are returned as they are
+func roundFn(name string, f func(float64) float64) *object.Builtin {
+	return &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+		}
+		if isInteger(args[0]) {
+			return args[0]
+		}
+		x, err := floatArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: f(x)}
+	}}
+}
+
+func atan2(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	y, err := floatArg("atan2", args[0])
+	if err != nil {
+		return err
+	}
+	x, err := floatArg("atan2", args[1])
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Atan2(y, x)}
+}
+
+// logFn is the natural log or the log in the base given as the second arg
+func logFn(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+	x, err := floatArg("log", args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return &object.Float{Value: math.Log(x)}
+	}
+	base, err := floatArg("log", args[1])
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Log(x) / math.Log(base)}
+}
+
+// abs keeps the type of the number. the absolute of the smallest int is a bigint
+func abs(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value == math.MinInt64 {
+			return &object.BigInt{Value: new(big.Int).Abs(big.NewInt(arg.Value))}
+		}
+		if arg.Value < 0 {
+			return &object.Integer{Value: -arg.Value}
+		}
+		return arg
+	case *object.BigInt:
+		return &object.BigInt{Value: new(big.Int).Abs(arg.Value)}
+	case *object.Rational:
+		return &object.Rational{Value: new(big.Rat).Abs(arg.Value)}
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}
+	default:
+		return newError(token.Position{}, "argument to 'abs' must be a number. got '%s'", args[0].Type())
+	}
+}
+
+func isNaN(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	x, err := floatArg("is_nan", args[0])
+	if err != nil {
+		return err
+	}
+	return boolToBoolean(math.IsNaN(x))
+}
+
+func isInf(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	x, err := floatArg("is_inf", args[0])
+	if err != nil {
+		return err
+	}
+	return boolToBoolean(math.IsInf(x, 0))
+}
+
+// intFromBig is an int if v fits in 64 bits otherwise a bigint
+func intFromBig(v *big.Int) object.Object {
+	if v.IsInt64() {
+		return &object.Integer{Value: v.Int64()}
+	}
+	return &object.BigInt{Value: v}
+}
+
+// toInt converts numbers, bools and decimal strings to an int. floats and rationals are truncated
+func toInt(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg
+	case *object.BigInt:
+		return intFromBig(arg.Value)
+	case *object.Rational:
+		return intFromBig(new(big.Int).Quo(arg.Value.Num(), arg.Value.Denom()))
+	case *object.Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+			return newError(token.Position{}, "cannot convert '%s' to int", arg)
+		}
+		v, _ := big.NewFloat(math.Trunc(arg.Value)).Int(nil)
+		return intFromBig(v)
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	case *object.String:
+		if v, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10); ok {
+			return intFromBig(v)
+		}
+		return newError(token.Position{}, "cannot convert '%s' to int", arg.Value)
+	default:
+		return newError(token.Position{}, "argument to 'int' not supported, got '%s'", args[0].Type())
+	}
+}
+
+// toFloat converts numbers, bools and strings to a float
+func toFloat(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInt, *object.Rational, *object.Float:
+		return promote(arg, object.FloatType)
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Float{Value: 1}
+		}
+		return &object.Float{Value: 0}
+	case *object.String:
+		v, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return newError(token.Position{}, "cannot convert '%s' to float", arg.Value)
+		}
+		return &object.Float{Value: v}
+	default:
+		return newError(token.Position{}, "argument to 'float' not supported, got '%s'", args[0].Type())
+	}
+}
+
+// toStr is the same string print would show
+func toStr(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	return stringify(call, args[0])
+}
+
+// toBool is whether the arg would pass an if condition
+func toBool(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	return boolToBoolean(isTruthy(args[0]))
+}
+
+// parseInt parses a string in a base from 2 to 36. base 0 uses the prefix of the string e.g 0x or 0b
+func parseInt(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+	s, ok := args[0].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "argument 1 to 'parse_int' must be a string. got '%s'", args[0].Type())
+	}
+	base, err := intArg("parse_int", args, 1, 10)
+	if err != nil {
+		return err
+	}
+	if base != 0 && (base < 2 || base > 36) {
+		return newError(token.Position{}, "base must be 0 or between 2 and 36. got '%d'", base)
+	}
+
+	v, ok := new(big.Int).SetString(strings.TrimSpace(s.Value), int(base))
+	if !ok {
+		return newError(token.Position{}, "cannot parse '%s' as an int in base %d", s.Value, base)
+	}
+	return intFromBig(v)
+}
@@ -0,0 +1,216 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"math"
+	"math/big"
+)
+
+// numericRank orders the number types by how much they can represent
+// a lower ranked number is premoted before applying an operator to two numbers
+// 0 means the object is not a number
+func numericRank(o object.Object) int {
+	switch o.Type() {
+	case object.IntType:
+		return 1
+	case object.BigIntType:
+		return 2
+	case object.RationalType:
+		return 3
+	case object.FloatType:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// isInteger is true for ints and bigints
+func isInteger(o object.Object) bool {
+	return o.Type() == object.IntType || o.Type() == object.BigIntType
+}
+
+// promote converts the number o to the higher ranked type t
+func promote(o object.Object, t object.Type) object.Object {
+	switch o := o.(type) {
+	case *object.Integer:
+		switch t {
+		case object.BigIntType:
+			return &object.BigInt{Value: big.NewInt(o.Value)}
+		case object.RationalType:
+			return &object.Rational{Value: new(big.Rat).SetInt64(o.Value)}
+		case object.FloatType:
+			return &object.Float{Value: float64(o.Value)}
+		}
+	case *object.BigInt:
+		switch t {
+		case object.RationalType:
+			return &object.Rational{Value: new(big.Rat).SetInt(o.Value)}
+		case object.FloatType:
+			f, _ := new(big.Float).SetInt(o.Value).Float64()
+			return &object.Float{Value: f}
+		}
+	case *object.Rational:
+		if t == object.FloatType {
+			f, _ := o.Value.Float64()
+			return &object.Float{Value: f}
+		}
+	}
+	return o
+}
+
+// maxBigIntBits is the most bits a bigint or the parts of a rational can grow to with ^ or <<.
+// bigger results would run out of memory or take too long to work out
+const maxBigIntBits = 1 << 24
+
+// powTooLarge is true if base ^ exp has more than maxBigIntBits bits.
+// a number with n bits is at least 2^(n-1) so this never refuses a result that fits
+func powTooLarge(base, exp *big.Int) bool {
+	bits := int64(base.BitLen() - 1)
+	if bits <= 0 || exp.Sign() <= 0 {
+		return false
+	}
+	return !exp.IsInt64() || exp.Int64() > maxBigIntBits/bits
+}
+
+// powInt raises base to a positive exp by squaring
+// returns false if the result overflows an int64
+func powInt(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			r := result * base
+			if result != 0 && (r/result != base || (result == -1 && base == math.MinInt64)) {
+				return 0, false
+			}
+			result = r
+		}
+		exp >>= 1
+		if exp > 0 {
+			b := base * base
+			if base != 0 && b/base != base {
+				return 0, false
+			}
+			base = b
+		}
+	}
+	return result, true
+}
+
+func evalBigIntInfixExpr(op token.Token, left, right object.Object) object.Object {
+	leftVal := left.(*object.BigInt).Value
+	rightVal := right.(*object.BigInt).Value
+
+	switch op.Type {
+	case token.Plus:
+		return &object.BigInt{Value: new(big.Int).Add(leftVal, rightVal)}
+	case token.Minus:
+		return &object.BigInt{Value: new(big.Int).Sub(leftVal, rightVal)}
+	case token.Times:
+		return &object.BigInt{Value: new(big.Int).Mul(leftVal, rightVal)}
+	case token.Divide:
+		if rightVal.Sign() == 0 {
+			return newError(op.Pos, "cannot divide %s by 0", leftVal)
+		}
+		q, _ := floorDivMod(leftVal, rightVal)
+		return &object.BigInt{Value: q}
+	case token.Mod:
+		if rightVal.Sign() == 0 {
+			return newError(op.Pos, "cannot modulo %s by 0", leftVal)
+		}
+		_, m := floorDivMod(leftVal, rightVal)
+		return &object.BigInt{Value: m}
+	case token.Exp:
+		if rightVal.Sign() < 0 {
+			return newError(op.Pos, "cannot raise bigint %s to negative power %s", leftVal, rightVal)
+		}
+		if powTooLarge(leftVal, rightVal) {
+			return newError(op.Pos, "result of %s ^ %s is too large", leftVal, rightVal)
+		}
+		return &object.BigInt{Value: new(big.Int).Exp(leftVal, rightVal, nil)}
+	case token.BitAnd:
+		return &object.BigInt{Value: new(big.Int).And(leftVal, rightVal)}
+	case token.Bar:
+		return &object.BigInt{Value: new(big.Int).Or(leftVal, rightVal)}
+	case token.Tilde:
+		return &object.BigInt{Value: new(big.Int).Xor(leftVal, rightVal)}
+	case token.ShiftLeft, token.ShiftRight:
+		if rightVal.Sign() < 0 || !rightVal.IsUint64() {
+			return newError(op.Pos, "cannot shift %s by count %s", leftVal, rightVal)
+		}
+		if op.Type == token.ShiftLeft {
+			if leftVal.Sign() != 0 && rightVal.Uint64() > maxBigIntBits {
+				return newError(op.Pos, "result of %s << %s is too large", leftVal, rightVal)
+			}
+			return &object.BigInt{Value: new(big.Int).Lsh(leftVal, uint(rightVal.Uint64()))}
+		}
+		return &object.BigInt{Value: new(big.Int).Rsh(leftVal, uint(rightVal.Uint64()))}
+	case token.Less:
+		return boolToBoolean(leftVal.Cmp(rightVal) < 0)
+	case token.Greater:
+		return boolToBoolean(leftVal.Cmp(rightVal) > 0)
+	case token.Equal:
+		return boolToBoolean(leftVal.Cmp(rightVal) == 0)
+	case token.NotEqual:
+		return boolToBoolean(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op.Type, left.Type(), right.Type())
+	}
+}
+
+// floorDivMod is the bigint version of floorDiv
+func floorDivMod(a, b *big.Int) (*big.Int, *big.Int) {
+	q, m := new(big.Int).QuoRem(a, b, new(big.Int))
+	if m.Sign() != 0 && m.Sign() != b.Sign() {
+		q.Sub(q, big.NewInt(1))
+		m.Add(m, b)
+	}
+	return q, m
+}
+
+func evalRationalInfixExpr(op token.Token, left, right object.Object) object.Object {
+	leftVal := left.(*object.Rational).Value
+	rightVal := right.(*object.Rational).Value
+
+	switch op.Type {
+	case token.Plus:
+		return &object.Rational{Value: new(big.Rat).Add(leftVal, rightVal)}
+	case token.Minus:
+		return &object.Rational{Value: new(big.Rat).Sub(leftVal, rightVal)}
+	case token.Times:
+		return &object.Rational{Value: new(big.Rat).Mul(leftVal, rightVal)}
+	case token.Divide:
+		if rightVal.Sign() == 0 {
+			return newError(op.Pos, "cannot divide %s by 0", leftVal.RatString())
+		}
+		return &object.Rational{Value: new(big.Rat).Quo(leftVal, rightVal)}
+	case token.Exp:
+		if !rightVal.IsInt() || !rightVal.Num().IsInt64() {
+			return newError(op.Pos, "cannot raise rational %s to non integer power %s", leftVal.RatString(), rightVal.RatString())
+		}
+		n := rightVal.Num().Int64()
+		if n < 0 && leftVal.Sign() == 0 {
+			return newError(op.Pos, "cannot raise 0 to negative power %d", n)
+		}
+		if n < 0 {
+			n = -n
+			leftVal = new(big.Rat).Inv(leftVal)
+		}
+		if powTooLarge(leftVal.Num(), big.NewInt(n)) || powTooLarge(leftVal.Denom(), big.NewInt(n)) {
+			return newError(op.Pos, "result of %s ^ %s is too large", left.(*object.Rational).Value.RatString(), rightVal.RatString())
+		}
+		num := new(big.Int).Exp(leftVal.Num(), big.NewInt(n), nil)
+		denom := new(big.Int).Exp(leftVal.Denom(), big.NewInt(n), nil)
+		return &object.Rational{Value: new(big.Rat).SetFrac(num, denom)}
+	case token.Less:
+		return boolToBoolean(leftVal.Cmp(rightVal) < 0)
+	case token.Greater:
+		return boolToBoolean(leftVal.Cmp(rightVal) > 0)
+	case token.Equal:
+		return boolToBoolean(leftVal.Cmp(rightVal) == 0)
+	case token.NotEqual:
+		return boolToBoolean(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op.Type, left.Type(), right.Type())
+	}
+}
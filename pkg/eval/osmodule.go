@@ -0,0 +1,183 @@
+package eval
+
+import (
+	"bytes"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AllowOS enables the os and fs namespaces and the in and out builtins.
+// turn it off before running untrusted scripts so they can't touch the system or its files
+var AllowOS = true
+
+// Args are the command line arguments given to the script
+var Args []string
+
+// Exit is called by os.exit. it can be replaced when embedding dusk
+var Exit = os.Exit
+
+func osModule() *object.Module {
+	return newModule("os", map[string]object.Object{
+		"env":      osFn(osEnv),
+		"set_env":  osFn(osSetEnv),
+		"args":     osFn(osArgs),
+		"cwd":      osFn(osCwd),
+		"chdir":    osFn(osChdir),
+		"exit":     osFn(osExit),
+		"hostname": osFn(osHostname),
+		"exec":     osFn(osExec),
+	})
+}
+
+// osFn makes a builtin that errors unless AllowOS is set
+func osFn(fn object.BuiltinFunction) *object.Builtin {
+	return restrictedFn("os", fn)
+}
+
+// restrictedFn makes a builtin that errors unless AllowOS is set. what names the functions in the error
+func restrictedFn(what string, fn object.BuiltinFunction) *object.Builtin {
+	return &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if !AllowOS {
+			return newError(token.Position{}, "%s functions are disabled", what)
+		}
+		return fn(call, args...)
+	}}
+}
+
+// osEnv is the value of an environment variable or nil if it isn't set.
+// with no args it's a map of every variable
+func osEnv(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("env", args, 0, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(strs) == 0 {
+		m := object.NewMap()
+		for _, kv := range os.Environ() {
+			parts := strings.SplitN(kv, "=", 2)
+			m.Set(&object.String{Value: parts[0]}, &object.String{Value: parts[1]})
+		}
+		return m
+	}
+
+	if v, ok := os.LookupEnv(strs[0]); ok {
+		return &object.String{Value: v}
+	}
+	return ConstNil
+}
+
+func osSetEnv(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("set_env", args, 2, 2, 2)
+	if err != nil {
+		return err
+	}
+	if err := os.Setenv(strs[0], strs[1]); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+func osArgs(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	return stringArray(Args)
+}
+
+func osCwd(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return osError(err)
+	}
+	return &object.String{Value: dir}
+}
+
+func osChdir(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("chdir", args, 1, 1, 1)
+	if errObj != nil {
+		return errObj
+	}
+	if err := os.Chdir(strs[0]); err != nil {
+		return osError(err)
+	}
+	return ConstNil
+}
+
+// osExit stops the program with a status code. the default is 0
+func osExit(call object.Caller, args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 1'", len(args))
+	}
+	code, err := intArg("exit", args, 0, 0)
+	if err != nil {
+		return err
+	}
+	Exit(int(code))
+	return ConstNil
+}
+
+func osHostname(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		return osError(err)
+	}
+	return &object.String{Value: name}
+}
+
+// osExec runs a program with an array of args and optional stdin.
+// it returns a map of the stdout, stderr and exit code
+func osExec(call object.Caller, args ...object.Object) object.Object {
+	strs, errObj := stringArgs("exec", args, 1, 3, 1)
+	if errObj != nil {
+		return errObj
+	}
+
+	cmdArgs := []string{}
+	if len(args) > 1 {
+		arr, ok := args[1].(*object.Array)
+		if !ok {
+			return newError(token.Position{}, "argument 2 to 'exec' must be an array. got '%s'", args[1].Type())
+		}
+		for _, e := range arr.Elements {
+			cmdArgs = append(cmdArgs, e.String())
+		}
+	}
+
+	cmd := exec.Command(strs[0], cmdArgs...)
+	if len(args) > 2 {
+		stdin, ok := args[2].(*object.String)
+		if !ok {
+			return newError(token.Position{}, "argument 3 to 'exec' must be a string. got '%s'", args[2].Type())
+		}
+		cmd.Stdin = strings.NewReader(stdin.Value)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	code := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return osError(err)
+		}
+		code = exitErr.ExitCode()
+	}
+
+	m := object.NewMap()
+	m.Set(&object.String{Value: "stdout"}, &object.String{Value: stdout.String()})
+	m.Set(&object.String{Value: "stderr"}, &object.String{Value: stderr.String()})
+	m.Set(&object.String{Value: "code"}, &object.Integer{Value: int64(code)})
+	return m
+}
@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+// operatorMethods are the special methods an instance can define to overload an operator.
+// method is called on the left operand. reflected is called on the right operand
+// when the left operand does not define method
+var operatorMethods = map[token.Type]struct{ method, reflected string }{
+	token.Plus:       {"__add", "__radd"},
+	token.Minus:      {"__sub", "__rsub"},
+	token.Times:      {"__mul", "__rmul"},
+	token.Divide:     {"__div", "__rdiv"},
+	token.Mod:        {"__mod", "__rmod"},
+	token.Exp:        {"__pow", "__rpow"},
+	token.BitAnd:     {"__and", "__rand"},
+	token.Bar:        {"__or", "__ror"},
+	token.Tilde:      {"__xor", "__rxor"},
+	token.ShiftLeft:  {"__shl", "__rshl"},
+	token.ShiftRight: {"__shr", "__rshr"},
+	token.Less:       {"__lt", "__gt"},
+	token.Greater:    {"__gt", "__lt"},
+	token.Equal:      {"__eq", "__eq"},
+	token.NotEqual:   {"__ne", "__ne"},
+}
+
+// specialMethod finds the method called name on an instance or a closure object.
+// a closure object is a function returned from a function that defined the method,
+// so only the variables of the function's own Environment count
+func specialMethod(o object.Object, name string) (object.Object, bool) {
+	var fn object.Object
+	var ok bool
+
+	switch o := o.(type) {
+	case *object.Instance:
+		fn, ok = o.Fields.Get(name)
+	case *object.Function:
+		fn, ok = o.Env.GetLocal(name)
+	}
+	if !ok || (fn.Type() != object.FunctionType && fn.Type() != object.BuiltinType) {
+		return nil, false
+	}
+	return fn, true
+}
+
+// evalOverloadedInfixExpr calls the special method for op if either operand defines it.
+// the left operand is tried first with the other operand as the argument.
+// then the reflected method of the right operand with the left operand as the argument.
+// != falls back to negating __eq. false if neither operand overloads op
+func evalOverloadedInfixExpr(op token.Token, left, right object.Object, env *object.Environment, stop <-chan struct{}) (object.Object, bool) {
+	methods, ok := operatorMethods[op.Type]
+	if !ok {
+		return nil, false
+	}
+
+	result, ok := callOperatorMethod(op, methods.method, left, right, env, stop)
+	if !ok {
+		result, ok = callOperatorMethod(op, methods.reflected, right, left, env, stop)
+	}
+
+	if !ok && op.Type == token.NotEqual {
+		equal := token.Token{Type: token.Equal, Literal: "==", Pos: op.Pos}
+		if result, ok = evalOverloadedInfixExpr(equal, left, right, env, stop); ok && !isError(result) {
+			return boolToBoolean(result == ConstFalse), true
+		}
+	}
+
+	return result, ok
+}
+
+func callOperatorMethod(op token.Token, name string, self, other object.Object, env *object.Environment, stop <-chan struct{}) (object.Object, bool) {
+	fn, ok := specialMethod(self, name)
+	if !ok {
+		return nil, false
+	}
+
+	result := doFunction(op, fn, []object.Object{other}, env, stop)
+	if op.Type == token.Equal || op.Type == token.NotEqual {
+		if isError(result) {
+			return result, true
+		}
+		return boolToBoolean(isTruthy(result)), true
+	}
+	return result, true
+}
+
+// evalSetIndex assigns to an index of an instance with its __setindex method
+// compound assignments read the current value with __index
+func evalSetIndex(node *ast.InfixExpression, target *ast.IndexExpression, instance, setIndex object.Object, env *object.Environment, stop <-chan struct{}) object.Object {
+	index := Eval(target.Index, env, stop)
+	if isError(index) {
+		return index
+	}
+
+	right := Eval(node.Right, env, stop)
+	if isError(right) {
+		return right
+	}
+
+	if node.Operator != token.Assign {
+		current := evalIndexExpr(target.Token, instance, index, env, stop)
+		if isError(current) {
+			return current
+		}
+
+		right = evalCompound(node, current, right, env, stop)
+		if isError(right) {
+			return right
+		}
+	}
+
+	if result := doFunction(target.Token, setIndex, []object.Object{index, right}, env, stop); isError(result) {
+		return result
+	}
+	return right
+}
+
+// stringify converts o to a string with its __str method if it has one
+func stringify(call object.Caller, o object.Object) object.Object {
+	fn, ok := specialMethod(o, "__str")
+	if !ok {
+		return &object.String{Value: o.String()}
+	}
+
+	result := call.Call(fn)
+	if isError(result) {
+		return result
+	}
+	return &object.String{Value: result.String()}
+}
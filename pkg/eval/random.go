@@ -0,0 +1,190 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"math"
+	"math/rand"
+)
+
+// randInt64n is a random int in [0, n) from the generator of the interpreter that called the builtin
+func randInt64n(call object.Caller, n int64) int64 {
+	rng := call.Env().Random()
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Int63n(n)
+}
+
+func randFloat64(call object.Caller) float64 {
+	rng := call.Env().Random()
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Float64()
+}
+
+// randRange is a random int in [min, max) for the builtin name
+func randRange(call object.Caller, name string, min, max int64) object.Object {
+	if max <= min {
+		return newError(token.Position{}, "max must be greater than min for '%s'. got min '%d' and max '%d'", name, min, max)
+	}
+
+	// max - min overflows an int64 for ranges wider than the largest int.
+	// the width always fits a uint64 so draw from that until one falls in the range
+	span := uint64(max) - uint64(min)
+	if span > math.MaxInt64 {
+		rng := call.Env().Random()
+		rng.Lock()
+		defer rng.Unlock()
+		for {
+			if v := rng.Uint64(); v < span {
+				return &object.Integer{Value: min + int64(v)}
+			}
+		}
+	}
+	return &object.Integer{Value: randInt64n(call, int64(span)) + min}
+}
+
+// randBetween reads the min and max args for the builtin name
+func randBetween(call object.Caller, name string, args []object.Object) object.Object {
+	min, err := intArg(name, args, 0, 0)
+	if err != nil {
+		return err
+	}
+	max, err := intArg(name, args, 1, 0)
+	if err != nil {
+		return err
+	}
+	return randRange(call, name, min, max)
+}
+
+// random is a float in [0, 1) or an int in [min, max)
+func random(call object.Caller, args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		return &object.Float{Value: randFloat64(call)}
+	case 2:
+		return randBetween(call, "rand", args)
+	default:
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 2'", len(args))
+	}
+}
+
+// seed resets the random number generator of the interpreter so the same numbers come out again.
+// it starts seeded with the time
+func seed(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	n, err := intArg("seed", args, 0, 0)
+	if err != nil {
+		return err
+	}
+	rng := call.Env().Random()
+	rng.Lock()
+	defer rng.Unlock()
+	rng.Rand = rand.New(rand.NewSource(n))
+	return ConstNil
+}
+
+// randInt is an int in [0, max) or [min, max)
+func randInt(call object.Caller, args ...object.Object) object.Object {
+	switch len(args) {
+	case 1:
+		max, err := intArg("rand_int", args, 0, 0)
+		if err != nil {
+			return err
+		}
+		return randRange(call, "rand_int", 0, max)
+	case 2:
+		return randBetween(call, "rand_int", args)
+	default:
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1 or 2'", len(args))
+	}
+}
+
+// randFloat is a float in [0, 1) or [min, max)
+func randFloat(call object.Caller, args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		return &object.Float{Value: randFloat64(call)}
+	case 2:
+		min, err := floatArg("rand_float", args[0])
+		if err != nil {
+			return err
+		}
+		max, err := floatArg("rand_float", args[1])
+		if err != nil {
+			return err
+		}
+		if max <= min {
+			return newError(token.Position{}, "max must be greater than min for 'rand_float'. got min '%s' and max '%s'", args[0], args[1])
+		}
+		return &object.Float{Value: min + randFloat64(call)*(max-min)}
+	default:
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 2'", len(args))
+	}
+}
+
+// choice is a random element of an array or character of a string
+func choice(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	items, err := elements(call, "choice", args[0])
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return newError(token.Position{}, "cannot choose from an empty %s", args[0].Type())
+	}
+	return items[randInt64n(call, int64(len(items)))]
+}
+
+// shuffle is a new array with the elements in a random order
+func shuffle(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+	}
+	items, err := elements(call, "shuffle", args[0])
+	if err != nil {
+		return err
+	}
+
+	shuffled := make([]object.Object, len(items))
+	copy(shuffled, items)
+
+	rng := call.Env().Random()
+	rng.Lock()
+	defer rng.Unlock()
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return &object.Array{Elements: shuffled}
+}
+
+// sample is k elements picked at random without picking the same one twice
+func sample(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	items, err := elements(call, "sample", args[0])
+	if err != nil {
+		return err
+	}
+	k, err := intArg("sample", args, 1, 0)
+	if err != nil {
+		return err
+	}
+	if k < 0 || k > int64(len(items)) {
+		return newError(token.Position{}, "cannot sample %d elements from %s of length %d", k, args[0].Type(), len(items))
+	}
+
+	rng := call.Env().Random()
+	rng.Lock()
+	defer rng.Unlock()
+	picked := make([]object.Object, k)
+	for i, j := range rng.Perm(len(items))[:k] {
+		picked[i] = items[j]
+	}
+	return &object.Array{Elements: picked}
+}
@@ -0,0 +1,221 @@
+package eval
+
+import (
+	"container/list"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"regexp"
+	"sync"
+)
+
+// maxCachedRegexes stops patterns built at runtime from growing the cache forever
+const maxCachedRegexes = 256
+
+// regexCache holds compiled regexes by pattern so re('...') in a loop only compiles once.
+// it stands in for regex literals cached at each place they're written.
+// when it's full the pattern used longest ago is dropped
+var regexCache = struct {
+	sync.Mutex
+	patterns map[string]*list.Element
+	order    *list.List // most recently used at the front. values are *cachedRegex
+}{patterns: map[string]*list.Element{}, order: list.New()}
+
+// cachedRegex is an entry in the regexCache
+type cachedRegex struct {
+	pattern string
+	regex   *object.Regex
+}
+
+// regexMethod is a builtin method of a regex
+type regexMethod func(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object
+
+var regexMethods = map[string]regexMethod{
+	"match":    regexMatch,
+	"find":     regexFind,
+	"find_all": regexFindAll,
+	"captures": regexCaptures,
+	"replace":  regexReplace,
+	"split":    regexSplit,
+}
+
+// re compiles a pattern into a regex
+func re(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("re", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	pattern := strs[0]
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if e, ok := regexCache.patterns[pattern]; ok {
+		regexCache.order.MoveToFront(e)
+		return e.Value.(*cachedRegex).regex
+	}
+
+	compiled, compileErr := regexp.Compile(pattern)
+	if compileErr != nil {
+		return newError(token.Position{}, "invalid regex '%s'. %s", pattern, compileErr)
+	}
+
+	if regexCache.order.Len() >= maxCachedRegexes {
+		oldest := regexCache.order.Back()
+		regexCache.order.Remove(oldest)
+		delete(regexCache.patterns, oldest.Value.(*cachedRegex).pattern)
+	}
+	r := newRegex(compiled)
+	regexCache.patterns[pattern] = regexCache.order.PushFront(&cachedRegex{pattern: pattern, regex: r})
+	return r
+}
+
+// newRegex makes a Regex with its methods bound to it
+func newRegex(compiled *regexp.Regexp) *object.Regex {
+	r := &object.Regex{Value: compiled, Env: object.NewEnvironment()}
+
+	for name, method := range regexMethods {
+		method := method
+		r.Env.SetConst(name, &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+			return method(compiled, call, args...)
+		}})
+	}
+	r.Env.SetConst("pattern", &object.String{Value: compiled.String()})
+
+	return r
+}
+
+// regexMatch is true if the regex matches anywhere in the string
+func regexMatch(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("match", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	return boolToBoolean(r.MatchString(strs[0]))
+}
+
+// regexFind is the first match or nil
+func regexFind(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("find", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	loc := r.FindStringIndex(strs[0])
+	if loc == nil {
+		return ConstNil
+	}
+	return &object.String{Value: strs[0][loc[0]:loc[1]]}
+}
+
+// regexFindAll is every match. the optional second arg limits the number of matches
+func regexFindAll(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("find_all", args, 1, 2, 1)
+	if err != nil {
+		return err
+	}
+	n, err := intArg("find_all", args, 1, -1)
+	if err != nil {
+		return err
+	}
+	return stringArray(r.FindAllString(strs[0], int(n)))
+}
+
+// regexCaptures is the groups of the first match or nil if there is no match.
+// patterns with named groups give a map of names to groups
+// otherwise an array of the whole match followed by each group.
+// groups that did not take part in the match are nil
+func regexCaptures(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("captures", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	loc := r.FindStringSubmatchIndex(strs[0])
+	if loc == nil {
+		return ConstNil
+	}
+
+	groups := make([]object.Object, len(loc)/2)
+	for i := range groups {
+		if loc[2*i] < 0 {
+			groups[i] = ConstNil
+		} else {
+			groups[i] = &object.String{Value: strs[0][loc[2*i]:loc[2*i+1]]}
+		}
+	}
+
+	named := object.NewMap()
+	for i, name := range r.SubexpNames() {
+		if name != "" {
+			named.Set(&object.String{Value: name}, groups[i])
+		}
+	}
+	if named.Len() > 0 {
+		return named
+	}
+	return &object.Array{Elements: groups}
+}
+
+// regexReplace replaces every match with a string or the result of calling a function with the match.
+// replacement strings can use $1 or ${name} for groups
+func regexReplace(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	s, ok := args[0].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "argument 1 to 'replace' must be a string. got '%s'", args[0].Type())
+	}
+
+	switch repl := args[1].(type) {
+	case *object.String:
+		return &object.String{Value: r.ReplaceAllString(s.Value, repl.Value)}
+	default:
+		if !isCallable(repl) {
+			return newError(token.Position{}, "argument 2 to 'replace' must be a string or function. got '%s'", repl.Type())
+		}
+
+		// the first error stops any more calls and is returned instead
+		var callErr object.Object
+		result := r.ReplaceAllStringFunc(s.Value, func(match string) string {
+			if callErr != nil {
+				return ""
+			}
+			out := call.Call(repl, &object.String{Value: match})
+			if isError(out) {
+				callErr = out
+				return ""
+			}
+			str := stringify(call, out)
+			if isError(str) {
+				callErr = str
+				return ""
+			}
+			return str.(*object.String).Value
+		})
+		if callErr != nil {
+			return callErr
+		}
+		return &object.String{Value: result}
+	}
+}
+
+// regexSplit splits the string around each match. the optional second arg limits the number of parts
+func regexSplit(r *regexp.Regexp, call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("split", args, 1, 2, 1)
+	if err != nil {
+		return err
+	}
+	n, err := intArg("split", args, 1, -1)
+	if err != nil {
+		return err
+	}
+	return stringArray(r.Split(strs[0], int(n)))
+}
+
+// stringArray makes an array of strings
+func stringArray(strs []string) *object.Array {
+	elems := make([]object.Object, len(strs))
+	for i, s := range strs {
+		elems[i] = &object.String{Value: s}
+	}
+	return &object.Array{Elements: elems}
+}
@@ -0,0 +1,228 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/ast"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+)
+
+// sliceIndex evaluates one bound of a slice, using def when it is left out
+func sliceIndex(node *ast.SliceExpression, e ast.Expression, def int64, env *object.Environment, stop <-chan struct{}) (int64, object.Object) {
+	if e == nil {
+		return def, nil
+	}
+
+	val := Eval(e, env, stop)
+	if isError(val) {
+		return 0, val
+	}
+	i, ok := val.(*object.Integer)
+	if !ok {
+		return 0, newError(node.Token.Pos, "slice index must be type 'int'. got type '%s'", val.Type())
+	}
+	return i.Value, nil
+}
+
+// sliceStep evaluates the step of a slice. it is evaluated before the start and end
+func sliceStep(node *ast.SliceExpression, env *object.Environment, stop <-chan struct{}) (int64, object.Object) {
+	step, err := sliceIndex(node, node.Step, 1, env, stop)
+	if err != nil {
+		return 0, err
+	}
+	if step == 0 {
+		return 0, newError(node.Token.Pos, "slice step cannot be 0")
+	}
+	return step, nil
+}
+
+// sliceBounds evaluates the start and end of a slice of a sequence of length n.
+// negative bounds count back from the end and bounds past either end are clamped.
+// a negative step walks backwards from the end
+func sliceBounds(node *ast.SliceExpression, n, step int64, env *object.Environment, stop <-chan struct{}) (start, end int64, err object.Object) {
+	// the lowest and highest an index can be. -1 means before the first element
+	low, high := int64(0), n
+	if step < 0 {
+		low, high = -1, n-1
+	}
+
+	clamp := func(i int64) int64 {
+		if i < 0 {
+			i += n
+		}
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+
+	defStart, defEnd := low, high
+	if step < 0 {
+		defStart, defEnd = high, low
+	}
+
+	if start, err = sliceIndex(node, node.Start, defStart, env, stop); err != nil {
+		return
+	}
+	if node.Start != nil {
+		start = clamp(start)
+	}
+
+	if end, err = sliceIndex(node, node.End, defEnd, env, stop); err != nil {
+		return
+	}
+	if node.End != nil {
+		end = clamp(end)
+	}
+
+	return start, end, nil
+}
+
+// sliceIndices lists the indices selected by start, end and step
+func sliceIndices(start, end, step int64) []int64 {
+	indices := []int64{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+func evalSliceExpr(node *ast.SliceExpression, env *object.Environment, stop <-chan struct{}) object.Object {
+	left := Eval(node.Left, env, stop)
+	if isError(left) {
+		return left
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		step, err := sliceStep(node, env, stop)
+		if err != nil {
+			return err
+		}
+		start, end, err := sliceBounds(node, int64(len(left.Elements)), step, env, stop)
+		if err != nil {
+			return err
+		}
+		return sliceArray(left, start, end, step)
+	case *object.String:
+		step, err := sliceStep(node, env, stop)
+		if err != nil {
+			return err
+		}
+
+		if step == 1 {
+			start, end, err := sliceBounds(node, int64(len(left.Value)), step, env, stop)
+			if err != nil {
+				return err
+			}
+			if end < start {
+				end = start
+			}
+			return &object.String{Value: left.Value[start:end]}
+		}
+
+		// stepping through bytes would split multi-byte characters so other steps go over runes
+		runes := []rune(left.Value)
+		start, end, err := sliceBounds(node, int64(len(runes)), step, env, stop)
+		if err != nil {
+			return err
+		}
+
+		str := []rune{}
+		for _, i := range sliceIndices(start, end, step) {
+			str = append(str, runes[i])
+		}
+		return &object.String{Value: string(str)}
+	default:
+		return newError(node.Token.Pos, "slice operator not supported on type '%s'", left.Type())
+	}
+}
+
+// sliceArray selects the elements of arr from start to end.
+// step 1 slices share elements with the array they came from.
+// the capacity is capped so growing the slice never writes into the array
+func sliceArray(arr *object.Array, start, end, step int64) *object.Array {
+	if step == 1 {
+		if end < start {
+			end = start
+		}
+		return &object.Array{Elements: arr.Elements[start:end:end], Frozen: arr.Frozen}
+	}
+
+	elems := []object.Object{}
+	for _, i := range sliceIndices(start, end, step) {
+		elems = append(elems, arr.Elements[i])
+	}
+	return &object.Array{Elements: elems}
+}
+
+// evalSliceAssign replaces the elements of an array selected by a slice.
+// step 1 slices can be replaced by an array of any length.
+// other steps need an array with one element for each index
+func evalSliceAssign(node *ast.InfixExpression, target *ast.SliceExpression, env *object.Environment, stop <-chan struct{}) object.Object {
+	left := Eval(target.Left, env, stop)
+	if isError(left) {
+		return left
+	}
+
+	arr, ok := left.(*object.Array)
+	if !ok {
+		return newError(target.Token.Pos, "slice operator assign not supported on type '%s'", left.Type())
+	}
+	if arr.Frozen {
+		return newError(target.Token.Pos, "cannot assign slice of frozen array")
+	}
+
+	step, err := sliceStep(target, env, stop)
+	if err != nil {
+		return err
+	}
+	start, end, err := sliceBounds(target, int64(len(arr.Elements)), step, env, stop)
+	if err != nil {
+		return err
+	}
+
+	right := Eval(node.Right, env, stop)
+	if isError(right) {
+		return right
+	}
+
+	if node.Operator != token.Assign {
+		right = evalCompound(node, sliceArray(arr, start, end, step), right, env, stop)
+		if isError(right) {
+			return right
+		}
+	}
+
+	values, ok := right.(*object.Array)
+	if !ok {
+		return newError(target.Token.Pos, "can only assign type 'array' to a slice. got type '%s'", right.Type())
+	}
+
+	if step == 1 {
+		if end < start {
+			end = start
+		}
+
+		elems := make([]object.Object, 0, int64(len(arr.Elements))-(end-start)+int64(len(values.Elements)))
+		elems = append(elems, arr.Elements[:start]...)
+		elems = append(elems, values.Elements...)
+		arr.Elements = append(elems, arr.Elements[end:]...)
+		return right
+	}
+
+	indices := sliceIndices(start, end, step)
+	if len(indices) != len(values.Elements) {
+		return newError(target.Token.Pos, "cannot assign array of length %d to slice of length %d", len(values.Elements), len(indices))
+	}
+
+	// copy first in case values is a slice of arr
+	elems := make([]object.Object, len(values.Elements))
+	copy(elems, values.Elements)
+	for j, i := range indices {
+		arr.Elements[i] = elems[j]
+	}
+	return right
+}
@@ -0,0 +1,292 @@
+package eval
+
+import (
+	"fmt"
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stringArgs checks a string builtin got between min and max args
+// and returns the first n args as strings
+func stringArgs(name string, args []object.Object, min, max, n int) ([]string, object.Object) {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return nil, newError(token.Position{}, "wrong number of arguments. got '%d', expected '%d'", len(args), min)
+		}
+		return nil, newError(token.Position{}, "wrong number of arguments. got '%d', expected '%d or %d'", len(args), min, max)
+	}
+
+	strs := make([]string, 0, n)
+	for i := 0; i < n && i < len(args); i++ {
+		s, ok := args[i].(*object.String)
+		if !ok {
+			return nil, newError(token.Position{}, "argument %d to '%s' must be a string. got '%s'", i+1, name, args[i].Type())
+		}
+		strs = append(strs, s.Value)
+	}
+	return strs, nil
+}
+
+// intArg returns the int argument at i or def if there aren't enough args
+func intArg(name string, args []object.Object, i int, def int64) (int64, object.Object) {
+	if i >= len(args) {
+		return def, nil
+	}
+	n, ok := args[i].(*object.Integer)
+	if !ok {
+		return 0, newError(token.Position{}, "argument %d to '%s' must be an int. got '%s'", i+1, name, args[i].Type())
+	}
+	return n.Value, nil
+}
+
+func upper(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("upper", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToUpper(strs[0])}
+}
+
+func lower(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("lower", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToLower(strs[0])}
+}
+
+// trimmer makes a trim builtin. with one arg whitespace is trimmed otherwise any characters in the second arg are
+func trimmer(name string, space func(string, func(rune) bool) string, cutset func(string, string) string) object.BuiltinFunction {
+	return func(call object.Caller, args ...object.Object) object.Object {
+		strs, err := stringArgs(name, args, 1, 2, 2)
+		if err != nil {
+			return err
+		}
+		if len(strs) == 1 {
+			return &object.String{Value: space(strs[0], unicode.IsSpace)}
+		}
+		return &object.String{Value: cutset(strs[0], strs[1])}
+	}
+}
+
+func startsWith(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("starts_with", args, 2, 2, 2)
+	if err != nil {
+		return err
+	}
+	return boolToBoolean(strings.HasPrefix(strs[0], strs[1]))
+}
+
+func endsWith(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("ends_with", args, 2, 2, 2)
+	if err != nil {
+		return err
+	}
+	return boolToBoolean(strings.HasSuffix(strs[0], strs[1]))
+}
+
+// stringIndex is the byte index of the first sub string or -1.
+// it's a byte index so it can be used to index and slice the string
+func stringIndex(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("index", args, 2, 2, 2)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: int64(strings.Index(strs[0], strs[1]))}
+}
+
+func replace(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("replace", args, 3, 4, 3)
+	if err != nil {
+		return err
+	}
+	n, err := intArg("replace", args, 3, -1)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], int(n))}
+}
+
+// maxStringBytes is the longest string repeat and the pad builtins will make
+const maxStringBytes = 1 << 30
+
+func repeat(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("repeat", args, 2, 2, 1)
+	if err != nil {
+		return err
+	}
+	n, err := intArg("repeat", args, 1, 0)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return newError(token.Position{}, "cannot repeat string a negative number of times. got '%d'", n)
+	}
+	if len(strs[0]) > 0 && n > maxStringBytes/int64(len(strs[0])) {
+		return newError(token.Position{}, "result of 'repeat' is too large")
+	}
+	return &object.String{Value: strings.Repeat(strs[0], int(n))}
+}
+
+// padder makes a pad builtin which pads a string to a width in characters
+func padder(name string, left bool) object.BuiltinFunction {
+	return func(call object.Caller, args ...object.Object) object.Object {
+		strs, err := stringArgs(name, args, 2, 3, 1)
+		if err != nil {
+			return err
+		}
+		width, err := intArg(name, args, 1, 0)
+		if err != nil {
+			return err
+		}
+		pad := " "
+		if len(args) == 3 {
+			p, ok := args[2].(*object.String)
+			if !ok || utf8.RuneCountInString(p.Value) != 1 {
+				return newError(token.Position{}, "third argument to '%s' must be a single character. got '%s'", name, args[2])
+			}
+			pad = p.Value
+		}
+
+		n := width - int64(utf8.RuneCountInString(strs[0]))
+		if n <= 0 {
+			return &object.String{Value: strs[0]}
+		}
+		if n > (maxStringBytes-int64(len(strs[0])))/int64(len(pad)) {
+			return newError(token.Position{}, "result of '%s' is too large", name)
+		}
+		if left {
+			return &object.String{Value: strings.Repeat(pad, int(n)) + strs[0]}
+		}
+		return &object.String{Value: strs[0] + strings.Repeat(pad, int(n))}
+	}
+}
+
+// lines splits a string on \n or \r\n. a trailing new line doesn't make an empty last line
+func lines(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("lines", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	elems := []object.Object{}
+	if len(strs[0]) == 0 {
+		return &object.Array{Elements: elems}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(strs[0], "\n"), "\n") {
+		elems = append(elems, &object.String{Value: strings.TrimSuffix(line, "\r")})
+	}
+	return &object.Array{Elements: elems}
+}
+
+func chars(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("chars", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	elems := []object.Object{}
+	for _, r := range strs[0] {
+		elems = append(elems, &object.String{Value: string(r)})
+	}
+	return &object.Array{Elements: elems}
+}
+
+// format replaces each verb in the first arg with the next argument
+// verbs are %d for ints, %f for numbers and %s or %v for any value.
+// they can have flags, a width and a precision like go's fmt. e.g %-5s or %08.3f
+func format(call object.Caller, args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '0', expected at least '1'")
+	}
+	f, ok := args[0].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "argument 1 to 'format' must be a string. got '%s'", args[0].Type())
+	}
+	args = args[1:]
+
+	var out strings.Builder
+	s := f.Value
+	next := 0
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		j := i + 1
+		for j < len(s) && strings.IndexByte("-+0 ", s[j]) >= 0 {
+			j++
+		}
+		for j < len(s) && isDigit(s[j]) {
+			j++
+		}
+		if j < len(s) && s[j] == '.' {
+			j++
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+		}
+		if j >= len(s) {
+			return newError(token.Position{}, "format ends with an incomplete verb '%s'", s[i:])
+		}
+
+		spec := s[i:j]
+		verb, size := utf8.DecodeRuneInString(s[j:])
+		i = j + size - 1
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if next >= len(args) {
+			return newError(token.Position{}, "missing argument for verb '%s%c' in format", spec, verb)
+		}
+
+		result, err := formatVerb(call, spec, verb, args[next])
+		if err != nil {
+			return err
+		}
+		out.WriteString(result)
+		next++
+	}
+
+	if next < len(args) {
+		return newError(token.Position{}, "format has %d verbs but got %d arguments", next, len(args))
+	}
+	return &object.String{Value: out.String()}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// formatVerb formats a single argument with the verb. spec is the verb up to the verb character
+func formatVerb(call object.Caller, spec string, verb rune, arg object.Object) (string, object.Object) {
+	switch verb {
+	case 'd':
+		switch arg := arg.(type) {
+		case *object.Integer:
+			return fmt.Sprintf(spec+"d", arg.Value), nil
+		case *object.BigInt:
+			return fmt.Sprintf(spec+"d", arg.Value), nil
+		}
+		return "", newError(token.Position{}, "verb '%sd' needs an int. got '%s'", spec, arg.Type())
+	case 'f':
+		if numericRank(arg) == 0 {
+			return "", newError(token.Position{}, "verb '%sf' needs a number. got '%s'", spec, arg.Type())
+		}
+		return fmt.Sprintf(spec+"f", promote(arg, object.FloatType).(*object.Float).Value), nil
+	case 's', 'v':
+		str := stringify(call, arg)
+		if isError(str) {
+			return "", str
+		}
+		return fmt.Sprintf(spec+"s", str.(*object.String).Value), nil
+	default:
+		return "", newError(token.Position{}, "unknown verb '%s%c' in format", spec, verb)
+	}
+}
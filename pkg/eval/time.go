@@ -0,0 +1,321 @@
+package eval
+
+import (
+	"jacob/dusk/pkg/object"
+	"jacob/dusk/pkg/token"
+	"math"
+	"time"
+
+	// embed the timezone database so in_zone works without one installed
+	_ "time/tzdata"
+)
+
+// started is when the program started. clock measures from it
+var started = time.Now()
+
+func timeModule() *object.Module {
+	return newModule("time", map[string]object.Object{
+		"now":     &object.Builtin{Fn: timeNow},
+		"unix":    &object.Builtin{Fn: timeUnix},
+		"date":    &object.Builtin{Fn: timeDate},
+		"format":  &object.Builtin{Fn: timeFormat},
+		"parse":   &object.Builtin{Fn: timeParse},
+		"in_zone": &object.Builtin{Fn: timeInZone},
+		"clock":   &object.Builtin{Fn: timeClock},
+
+		"duration": &object.Builtin{Fn: timeDuration},
+		"ms":       durationFn("ms", time.Millisecond),
+		"seconds":  durationFn("seconds", time.Second),
+		"minutes":  durationFn("minutes", time.Minute),
+		"hours":    durationFn("hours", time.Hour),
+
+		"rfc3339":   &object.String{Value: time.RFC3339},
+		"date_only": &object.String{Value: "2006-01-02"},
+		"datetime":  &object.String{Value: "2006-01-02 15:04:05"},
+		"kitchen":   &object.String{Value: time.Kitchen},
+	})
+}
+
+// newTime makes a Time with its parts as members
+func newTime(t time.Time) *object.Time {
+	env := object.NewEnvironment()
+	members := map[string]object.Object{
+		"year":       &object.Integer{Value: int64(t.Year())},
+		"month":      &object.Integer{Value: int64(t.Month())},
+		"day":        &object.Integer{Value: int64(t.Day())},
+		"hour":       &object.Integer{Value: int64(t.Hour())},
+		"minute":     &object.Integer{Value: int64(t.Minute())},
+		"second":     &object.Integer{Value: int64(t.Second())},
+		"nanosecond": &object.Integer{Value: int64(t.Nanosecond())},
+		"weekday":    &object.String{Value: t.Weekday().String()},
+		"yearday":    &object.Integer{Value: int64(t.YearDay())},
+		"unix":       &object.Integer{Value: t.Unix()},
+		"unix_ms":    &object.Integer{Value: t.UnixNano() / int64(time.Millisecond)},
+	}
+	zone, _ := t.Zone()
+	members["zone"] = &object.String{Value: zone}
+	for k, v := range members {
+		env.SetConst(k, v)
+	}
+	return &object.Time{Value: t, Env: env}
+}
+
+// newDuration makes a Duration with its length in different units as members
+func newDuration(d time.Duration) *object.Duration {
+	env := object.NewEnvironment()
+	env.SetConst("hours", &object.Float{Value: d.Hours()})
+	env.SetConst("minutes", &object.Float{Value: d.Minutes()})
+	env.SetConst("seconds", &object.Float{Value: d.Seconds()})
+	env.SetConst("ms", &object.Integer{Value: int64(d / time.Millisecond)})
+	env.SetConst("ns", &object.Integer{Value: int64(d)})
+	return &object.Duration{Value: d, Env: env}
+}
+
+// timeArg returns the time argument at i
+func timeArg(name string, args []object.Object, i int) (time.Time, object.Object) {
+	t, ok := args[i].(*object.Time)
+	if !ok {
+		return time.Time{}, newError(token.Position{}, "argument %d to '%s' must be a time. got '%s'", i+1, name, args[i].Type())
+	}
+	return t.Value, nil
+}
+
+func timeNow(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	return newTime(time.Now())
+}
+
+// timeUnix is the current unix time in seconds. with a number of seconds it's the time at that unix time
+func timeUnix(call object.Caller, args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0 or 1'", len(args))
+	}
+	if len(args) == 0 {
+		return &object.Integer{Value: time.Now().Unix()}
+	}
+
+	secs, err := floatArg("unix", args[0])
+	if err != nil {
+		return err
+	}
+	whole, frac := math.Modf(secs)
+	return newTime(time.Unix(int64(whole), int64(frac*float64(time.Second))))
+}
+
+// timeDate makes a local time from a year, month, day and optional hour, minute and second
+func timeDate(call object.Caller, args ...object.Object) object.Object {
+	if len(args) < 3 || len(args) > 6 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected between '3' and '6'", len(args))
+	}
+
+	parts := make([]int, 6)
+	for i := range args {
+		n, err := intArg("date", args, i, 0)
+		if err != nil {
+			return err
+		}
+		parts[i] = int(n)
+	}
+	return newTime(time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.Local))
+}
+
+// timeFormat formats a time with a go layout such as '2006-01-02 15:04'
+func timeFormat(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	t, err := timeArg("format", args, 0)
+	if err != nil {
+		return err
+	}
+	layout, ok := args[1].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "argument 2 to 'format' must be a string. got '%s'", args[1].Type())
+	}
+	return &object.String{Value: t.Format(layout.Value)}
+}
+
+// timeParse parses a string with a go layout. times without a zone are in the optional zone or UTC
+func timeParse(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("parse", args, 2, 3, 3)
+	if err != nil {
+		return err
+	}
+
+	loc := time.UTC
+	if len(strs) == 3 {
+		l, locErr := time.LoadLocation(strs[2])
+		if locErr != nil {
+			return newError(token.Position{}, "unknown time zone '%s'", strs[2])
+		}
+		loc = l
+	}
+
+	t, parseErr := time.ParseInLocation(strs[1], strs[0], loc)
+	if parseErr != nil {
+		return newError(token.Position{}, "%s", parseErr)
+	}
+	return newTime(t)
+}
+
+// timeInZone is the same time in a zone such as 'Asia/Tokyo', 'UTC' or 'Local'
+func timeInZone(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '2'", len(args))
+	}
+	t, err := timeArg("in_zone", args, 0)
+	if err != nil {
+		return err
+	}
+	name, ok := args[1].(*object.String)
+	if !ok {
+		return newError(token.Position{}, "argument 2 to 'in_zone' must be a string. got '%s'", args[1].Type())
+	}
+
+	loc, locErr := time.LoadLocation(name.Value)
+	if locErr != nil {
+		return newError(token.Position{}, "unknown time zone '%s'", name.Value)
+	}
+	return newTime(t.In(loc))
+}
+
+// timeClock is a monotonic duration since the program started for timing code
+func timeClock(call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError(token.Position{}, "wrong number of arguments. got '%d', expected '0'", len(args))
+	}
+	return newDuration(time.Since(started))
+}
+
+// timeDuration parses a duration such as '1h30m' or '250ms'
+func timeDuration(call object.Caller, args ...object.Object) object.Object {
+	strs, err := stringArgs("duration", args, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	d, parseErr := time.ParseDuration(strs[0])
+	if parseErr != nil {
+		return newError(token.Position{}, "%s", parseErr)
+	}
+	return newDuration(d)
+}
+
+// durationFn makes a builtin that makes a duration of a number of units
+func durationFn(name string, unit time.Duration) *object.Builtin {
+	return &object.Builtin{Fn: func(call object.Caller, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(token.Position{}, "wrong number of arguments. got '%d', expected '1'", len(args))
+		}
+		if n, ok := args[0].(*object.Integer); ok {
+			if n.Value > math.MaxInt64/int64(unit) || n.Value < math.MinInt64/int64(unit) {
+				return newError(token.Position{}, "%s %s is out of range of a duration", n, name)
+			}
+			return newDuration(time.Duration(n.Value) * unit)
+		}
+		n, err := floatArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		nanos := n * float64(unit)
+		if !inDurationRange(nanos) {
+			return newError(token.Position{}, "%s %s is out of range of a duration", args[0], name)
+		}
+		return newDuration(time.Duration(nanos))
+	}}
+}
+
+// evalTimeInfixExpr applies operators to times and durations.
+// a time plus or minus a duration is a time and the difference of two times is a duration
+func evalTimeInfixExpr(op token.Token, left, right object.Object) object.Object {
+	switch l := left.(type) {
+	case *object.Time:
+		switch r := right.(type) {
+		case *object.Duration:
+			switch op.Type {
+			case token.Plus:
+				return newTime(l.Value.Add(r.Value))
+			case token.Minus:
+				return newTime(l.Value.Add(-r.Value))
+			}
+		case *object.Time:
+			switch op.Type {
+			case token.Minus:
+				return newDuration(l.Value.Sub(r.Value))
+			case token.Less:
+				return boolToBoolean(l.Value.Before(r.Value))
+			case token.Greater:
+				return boolToBoolean(l.Value.After(r.Value))
+			case token.Equal:
+				return boolToBoolean(l.Value.Equal(r.Value))
+			case token.NotEqual:
+				return boolToBoolean(!l.Value.Equal(r.Value))
+			}
+		}
+	case *object.Duration:
+		switch r := right.(type) {
+		case *object.Time:
+			if op.Type == token.Plus {
+				return newTime(r.Value.Add(l.Value))
+			}
+		case *object.Duration:
+			switch op.Type {
+			case token.Plus:
+				return newDuration(l.Value + r.Value)
+			case token.Minus:
+				return newDuration(l.Value - r.Value)
+			case token.Divide:
+				if r.Value == 0 {
+					return newError(op.Pos, "cannot divide %s by 0", l)
+				}
+				return &object.Float{Value: float64(l.Value) / float64(r.Value)}
+			case token.Less:
+				return boolToBoolean(l.Value < r.Value)
+			case token.Greater:
+				return boolToBoolean(l.Value > r.Value)
+			case token.Equal:
+				return boolToBoolean(l.Value == r.Value)
+			case token.NotEqual:
+				return boolToBoolean(l.Value != r.Value)
+			}
+		case *object.Integer, *object.Float:
+			n := promote(r, object.FloatType).(*object.Float).Value
+			switch op.Type {
+			case token.Times:
+				return scaleDuration(op, l, float64(l.Value)*n)
+			case token.Divide:
+				if n == 0 {
+					return newError(op.Pos, "cannot divide %s by 0", l)
+				}
+				return scaleDuration(op, l, float64(l.Value)/n)
+			}
+		}
+	case *object.Integer, *object.Float:
+		if r, ok := right.(*object.Duration); ok && op.Type == token.Times {
+			n := promote(l, object.FloatType).(*object.Float).Value
+			return scaleDuration(op, r, n*float64(r.Value))
+		}
+	}
+
+	switch op.Type {
+	case token.Equal:
+		return ConstFalse
+	case token.NotEqual:
+		return ConstTrue
+	}
+	return newError(op.Pos, "unknown operator '%s' for type '%s' and '%s'", op, left.Type(), right.Type())
+}
+
+// scaleDuration is a duration of nanos nanoseconds. an error if it's too long for a duration
+func scaleDuration(op token.Token, d *object.Duration, nanos float64) object.Object {
+	if !inDurationRange(nanos) {
+		return newError(op.Pos, "duration out of range scaling %s", d)
+	}
+	return newDuration(time.Duration(nanos))
+}
+
+// inDurationRange is true if a number of nanoseconds fits in a duration
+func inDurationRange(nanos float64) bool {
+	return !math.IsNaN(nanos) && nanos >= math.MinInt64 && nanos < math.MaxInt64
+}
@@ -75,75 +75,76 @@ func (l *Lexer) Next() (token.Token, error) {
 	switch l.char {
 	case '=':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.nextChar()
-
-			b := make([]byte, 2)
-			b[0] = char
-			b[1] = l.char
-
-			tok = token.Token{Type: token.Equal, Literal: string(b), Pos: l.pos}
+			tok = l.readPair(token.Equal)
+		} else if l.peekChar() == '>' {
+			tok = l.readPair(token.Arrow)
 		} else {
 			tok = token.New(token.Assign, l.char, l.pos)
 		}
 	case '+':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.nextChar()
-
-			b := make([]byte, 2)
-			b[0] = char
-			b[1] = l.char
-
-			tok = token.Token{Type: token.Inc, Literal: string(b), Pos: l.pos}
+			tok = l.readPair(token.Inc)
 		} else {
 			tok = token.New(token.Plus, l.char, l.pos)
 		}
 	case '-':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.nextChar()
-
-			b := make([]byte, 2)
-			b[0] = char
-			b[1] = l.char
-
-			tok = token.Token{Type: token.Dec, Literal: string(b), Pos: l.pos}
+			tok = l.readPair(token.Dec)
 		} else {
 			tok = token.New(token.Minus, l.char, l.pos)
 		}
 	case '*':
-		tok = token.New(token.Times, l.char, l.pos)
+		if l.peekChar() == '=' {
+			tok = l.readPair(token.MulAssign)
+		} else {
+			tok = token.New(token.Times, l.char, l.pos)
+		}
 	case '/':
 		if l.peekChar() == '/' {
 			l.consumeComment()
 			return l.Next()
 		}
-		tok = token.New(token.Divide, l.char, l.pos)
-
+		if l.peekChar() == '=' {
+			tok = l.readPair(token.DivAssign)
+		} else {
+			tok = token.New(token.Divide, l.char, l.pos)
+		}
 	case '^':
-		tok = token.New(token.Exp, l.char, l.pos)
+		if l.peekChar() == '=' {
+			tok = l.readPair(token.ExpAssign)
+		} else {
+			tok = token.New(token.Exp, l.char, l.pos)
+		}
 	case '%':
-		tok = token.New(token.Mod, l.char, l.pos)
+		if l.peekChar() == '=' {
+			tok = l.readPair(token.ModAssign)
+		} else {
+			tok = token.New(token.Mod, l.char, l.pos)
+		}
 	case '!':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.nextChar()
-
-			b := make([]byte, 2)
-			b[0] = char
-			b[1] = l.char
-
-			tok = token.Token{Type: token.NotEqual, Literal: string(b), Pos: l.pos}
+			tok = l.readPair(token.NotEqual)
 		} else {
 			tok = token.New(token.Bang, l.char, l.pos)
 		}
 	case ':':
 		tok = token.New(token.Continue, l.char, l.pos)
 	case '<':
-		tok = token.New(token.Less, l.char, l.pos)
+		if l.peekChar() == '<' {
+			tok = l.readPair(token.ShiftLeft)
+		} else {
+			tok = token.New(token.Less, l.char, l.pos)
+		}
 	case '>':
-		tok = token.New(token.Greater, l.char, l.pos)
+		if l.peekChar() == '>' {
+			tok = l.readPair(token.ShiftRight)
+		} else {
+			tok = token.New(token.Greater, l.char, l.pos)
+		}
+	case '&':
+		tok = token.New(token.BitAnd, l.char, l.pos)
+	case '~':
+		tok = token.New(token.Tilde, l.char, l.pos)
 	case '{':
 		tok = token.New(token.LBrace, l.char, l.pos)
 		l.stack = append(l.stack, tok.Type)
@@ -169,7 +170,16 @@ func (l *Lexer) Next() (token.Token, error) {
 	case ';':
 		tok = token.New(token.Terminator, l.char, l.pos)
 	case '.':
-		tok = token.New(token.Dot, l.char, l.pos)
+		if l.peekChar() == '.' && l.peekCharAt(2) == '.' {
+			pos := l.pos
+			l.nextChar()
+			l.nextChar()
+			tok = token.Token{Type: token.Ellipsis, Literal: "...", Pos: pos}
+		} else if l.peekChar() == '.' {
+			tok = l.readPair(token.Range)
+		} else {
+			tok = token.New(token.Dot, l.char, l.pos)
+		}
 	case 0:
 		tok = token.New(token.EOF, l.char, l.pos)
 		if len(l.stack) > 0 {
@@ -246,8 +256,8 @@ func (l *Lexer) readNumber() token.Token {
 
 	l.last = token.Int
 
-	// read decimal number
-	if l.char == '.' {
+	// read decimal number. 1..2 is a range not a float
+	if l.char == '.' && isDigit(l.peekChar()) {
 		l.nextChar()
 		for isDigit(l.char) {
 			l.nextChar()
@@ -298,6 +308,14 @@ func (l *Lexer) nextChar() byte {
 	return l.char
 }
 
+// readPair reads the current and next character as a token of type t
+func (l *Lexer) readPair(t token.Type) token.Token {
+	pos := l.pos
+	b := []byte{l.char, l.peekChar()}
+	l.nextChar()
+	return token.Token{Type: t, Literal: string(b), Pos: pos}
+}
+
 func (l *Lexer) peekChar() byte {
 	if l.next >= len(l.buff) {
 		return 0
@@ -305,6 +323,14 @@ func (l *Lexer) peekChar() byte {
 	return l.buff[l.next]
 }
 
+// peekCharAt reads the character n places ahead of the current character
+func (l *Lexer) peekCharAt(n int) byte {
+	if l.curr+n >= len(l.buff) {
+		return 0
+	}
+	return l.buff[l.curr+n]
+}
+
 // popCheck brace returning error if Unbalanced
 func (l *Lexer) popCheck(match token.Type, tok token.Token) error {
 	last := len(l.stack) - 1
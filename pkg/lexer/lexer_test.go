@@ -23,6 +23,9 @@ let fail = 21
 "foobar"
 "foo bar"
 "foo\tbar""foo\nbar"
+a & b ~ ~c << 1 >> 2
+for [a, ...b] in c.d
+match a { 1..2.5 => b }
 `
 
 	tests := []struct {
@@ -92,7 +95,40 @@ let fail = 21
 		{token.String, "foo\tbar"},
 		{token.String, "foo\nbar"},
 		{token.Terminator, ";"},
-		{token.EOF, string(0)},
+		{token.Identifier, "a"},
+		{token.BitAnd, "&"},
+		{token.Identifier, "b"},
+		{token.Tilde, "~"},
+		{token.Tilde, "~"},
+		{token.Identifier, "c"},
+		{token.ShiftLeft, "<<"},
+		{token.Int, "1"},
+		{token.ShiftRight, ">>"},
+		{token.Int, "2"},
+		{token.Terminator, ";"},
+		{token.For, "for"},
+		{token.LBracket, "["},
+		{token.Identifier, "a"},
+		{token.Comma, ","},
+		{token.Ellipsis, "..."},
+		{token.Identifier, "b"},
+		{token.RBracket, "]"},
+		{token.Identifier, "in"},
+		{token.Identifier, "c"},
+		{token.Dot, "."},
+		{token.Identifier, "d"},
+		{token.Terminator, ";"},
+		{token.Match, "match"},
+		{token.Identifier, "a"},
+		{token.LBrace, "{"},
+		{token.Int, "1"},
+		{token.Range, ".."},
+		{token.Float, "2.5"},
+		{token.Arrow, "=>"},
+		{token.Identifier, "b"},
+		{token.RBrace, "}"},
+		{token.Terminator, ";"},
+		{token.EOF, string(rune(0))},
 	}
 
 	l := WithString(input, "lexer_test.go")
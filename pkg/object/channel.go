@@ -0,0 +1,32 @@
+package object
+
+import (
+	"fmt"
+	"jacob/dusk/pkg/token"
+)
+
+// Channel passes values between spawned functions. its methods are members bound to it
+type Channel struct {
+	Value chan Object
+	Env   *Environment
+}
+
+// String for Channel
+func (c *Channel) String() string {
+	return fmt.Sprintf("chan(%d)", cap(c.Value))
+}
+
+// Type for Channel
+func (c *Channel) Type() Type {
+	return ChannelType
+}
+
+// CanApply for this type
+func (c *Channel) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// Members of a Channel are its methods
+func (c *Channel) Members() *Environment {
+	return c.Env
+}
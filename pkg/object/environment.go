@@ -1,15 +1,32 @@
 package object
 
-// Environment stores the variables for a context
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Environment stores the variables for a context.
+// it's locked so spawned functions can share it
 type Environment struct {
+	mu     sync.RWMutex
 	vars   map[string]Object
+	consts map[string]bool
 	parent *Environment
+	random *Random
+}
+
+// Random is the random number generator of one interpreter.
+// it's locked so spawned functions can share it
+type Random struct {
+	sync.Mutex
+	*rand.Rand
 }
 
 // NewEnvironment makes a new Environment with no values
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{s, nil}
+	return &Environment{vars: s}
 }
 
 // NewChildEnvironment creates an enclosed Environment on the parent
@@ -21,25 +38,90 @@ func NewChildEnvironment(parent *Environment) *Environment {
 
 // Get a value from the variables map
 func (e *Environment) Get(name string) (Object, bool) {
+	e.mu.RLock()
 	o, ok := e.vars[name]
+	e.mu.RUnlock()
 	if !ok && e.parent != nil {
 		o, ok = e.parent.Get(name)
 	}
 	return o, ok
 }
 
+// GetLocal gets a value from this Environment without looking in its parents
+func (e *Environment) GetLocal(name string) (Object, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	o, ok := e.vars[name]
+	return o, ok
+}
+
 // Set a value in the varibles map
 func (e *Environment) Set(name string, val Object) Object {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.vars[name] = val
 	return val
 }
 
 // Assign a existing value in the varibles map
 func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	e.mu.Lock()
 	_, ok := e.vars[name]
 	if !ok && e.parent != nil {
+		e.mu.Unlock()
 		return e.parent.Assign(name, val)
 	}
 	e.vars[name] = val
+	e.mu.Unlock()
 	return val, ok
 }
+
+// SetConst sets a value in the variables map that cannot be reassigned
+func (e *Environment) SetConst(name string, val Object) Object {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+	e.vars[name] = val
+	return val
+}
+
+// IsLocalConst checks if name is a constant declared in this Environment, not its parents
+func (e *Environment) IsLocalConst(name string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.consts[name]
+}
+
+// IsConst checks if the variable name resolves to a constant
+func (e *Environment) IsConst(name string) bool {
+	e.mu.RLock()
+	_, ok := e.vars[name]
+	isConst := e.consts[name]
+	e.mu.RUnlock()
+	if ok {
+		return isConst
+	}
+	if e.parent != nil {
+		return e.parent.IsConst(name)
+	}
+	return false
+}
+
+// Random is the random number generator of the program. it's kept in the outermost
+// Environment so each interpreter has its own. it starts seeded with the time
+func (e *Environment) Random() *Random {
+	root := e
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.random == nil {
+		root.random = &Random{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	}
+	return root.random
+}
@@ -0,0 +1,29 @@
+package object
+
+import "jacob/dusk/pkg/token"
+
+// File is an open file handle. its methods are members bound to it
+type File struct {
+	Path string
+	Env  *Environment
+}
+
+// String for File
+func (f *File) String() string {
+	return "file('" + f.Path + "')"
+}
+
+// Type for File
+func (f *File) Type() Type {
+	return FileType
+}
+
+// CanApply for this type
+func (f *File) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// Members of a File are its methods
+func (f *File) Members() *Environment {
+	return f.Env
+}
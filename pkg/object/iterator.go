@@ -0,0 +1,30 @@
+package object
+
+import "jacob/dusk/pkg/token"
+
+// Iterator makes values one at a time. its methods are members bound to it
+type Iterator struct {
+	Next  func() (Object, bool) // the next value. false when there are no more
+	Close func()                // stops the iterator early
+	Env   *Environment
+}
+
+// String for Iterator
+func (i *Iterator) String() string {
+	return "iterator"
+}
+
+// Type for Iterator
+func (i *Iterator) Type() Type {
+	return IteratorType
+}
+
+// CanApply for this type
+func (i *Iterator) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// Members of an Iterator are its methods
+func (i *Iterator) Members() *Environment {
+	return i.Env
+}
@@ -0,0 +1,166 @@
+package object
+
+import (
+	"bytes"
+	"jacob/dusk/pkg/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HashKey identifies a value used as a key in a Map
+type HashKey struct {
+	Type  Type
+	Value string
+}
+
+// Hashable objects can be used as keys in a Map
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+// HashKey for Integer
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: IntType, Value: strconv.FormatInt(i.Value, 10)}
+}
+
+// HashKey for BigInt. the same key as an equal Integer
+func (i *BigInt) HashKey() HashKey {
+	return HashKey{Type: IntType, Value: i.Value.String()}
+}
+
+// HashKey for Float. whole numbers use the key of the equal Integer since 1 == 1.0
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		i, _ := big.NewFloat(f.Value).Int(nil)
+		return HashKey{Type: IntType, Value: i.String()}
+	}
+	return HashKey{Type: FloatType, Value: strconv.FormatFloat(f.Value, 'g', -1, 64)}
+}
+
+// HashKey for Boolean
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: BooleanType, Value: strconv.FormatBool(b.Value)}
+}
+
+// HashKey for String
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: StringType, Value: s.Value}
+}
+
+// MapPair is a key and its value
+type MapPair struct {
+	Key   Object
+	Value Object
+}
+
+// Map is a hash map that remembers the order keys were added.
+// it's locked so spawned functions can share it
+type Map struct {
+	mu     sync.RWMutex
+	pairs  map[HashKey]*MapPair
+	order  []HashKey
+	Frozen bool // frozen maps cannot be modified
+}
+
+// NewMap makes an empty Map
+func NewMap() *Map {
+	return &Map{pairs: make(map[HashKey]*MapPair)}
+}
+
+// Get the value for key
+func (m *Map) Get(key Hashable) (Object, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pair, ok := m.pairs[key.HashKey()]
+	if !ok {
+		return nil, false
+	}
+	return pair.Value, true
+}
+
+// Set the value for key. new keys go at the end
+func (m *Map) Set(key Hashable, val Object) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hash := key.HashKey()
+	if pair, ok := m.pairs[hash]; ok {
+		pair.Value = val
+		return
+	}
+
+	m.pairs[hash] = &MapPair{Key: key, Value: val}
+	m.order = append(m.order, hash)
+}
+
+// Delete key from the map. returns the value it had
+func (m *Map) Delete(key Hashable) (Object, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hash := key.HashKey()
+	pair, ok := m.pairs[hash]
+	if !ok {
+		return nil, false
+	}
+
+	delete(m.pairs, hash)
+	for i, h := range m.order {
+		if h == hash {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return pair.Value, true
+}
+
+// Len is the number of keys in the map
+func (m *Map) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.order)
+}
+
+// Pairs of the map in the order the keys were added
+func (m *Map) Pairs() []*MapPair {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pairs := make([]*MapPair, len(m.order))
+	for i, h := range m.order {
+		pairs[i] = m.pairs[h]
+	}
+	return pairs
+}
+
+// String for Map
+func (m *Map) String() string {
+	var b bytes.Buffer
+
+	pairs := []string{}
+	for _, p := range m.Pairs() {
+		pairs = append(pairs, p.Key.String()+": "+p.Value.String())
+	}
+
+	b.WriteString("{")
+	b.WriteString(strings.Join(pairs, ", "))
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// Type for Map
+func (m *Map) Type() Type {
+	return MapType
+}
+
+// CanApply for this type
+func (m *Map) CanApply(op token.Type, t Type) bool {
+	switch op {
+	case token.Equal, token.NotEqual:
+		return true
+	default:
+		return false
+	}
+}
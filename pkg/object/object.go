@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"jacob/dusk/pkg/ast"
 	"jacob/dusk/pkg/token"
+	"math/big"
 	"strings"
 )
 
@@ -32,6 +33,30 @@ const (
 	BuiltinType
 	// ArrayType is an array of any objects
 	ArrayType
+	// BigIntType arbitrary precision int
+	BigIntType
+	// RationalType exact fraction of two arbitrary precision ints
+	RationalType
+	// ClassType is a class declaration
+	ClassType
+	// InstanceType is an instance of a class
+	InstanceType
+	// MapType is a hash map of keys to values in insertion order
+	MapType
+	// ModuleType is a namespace of builtins
+	ModuleType
+	// RegexType is a compiled regular expression
+	RegexType
+	// FileType is an open file handle
+	FileType
+	// TimeType is an instant in time
+	TimeType
+	// DurationType is the time between two instants
+	DurationType
+	// ChannelType passes values between spawned functions
+	ChannelType
+	// IteratorType makes values one at a time
+	IteratorType
 )
 
 // String for type
@@ -55,6 +80,30 @@ func (t Type) String() string {
 		return "builtin"
 	case ArrayType:
 		return "array"
+	case BigIntType:
+		return "bigint"
+	case RationalType:
+		return "rational"
+	case ClassType:
+		return "class"
+	case InstanceType:
+		return "instance"
+	case MapType:
+		return "map"
+	case ModuleType:
+		return "module"
+	case RegexType:
+		return "regex"
+	case FileType:
+		return "file"
+	case TimeType:
+		return "time"
+	case DurationType:
+		return "duration"
+	case ChannelType:
+		return "channel"
+	case IteratorType:
+		return "iterator"
 	default:
 		return "unknown"
 	}
@@ -67,6 +116,12 @@ type Object interface {
 	CanApply(token.Type, Type) bool
 }
 
+// Scope is an object with members that can be reached with the '.' operator
+type Scope interface {
+	Object
+	Members() *Environment
+}
+
 // Integer is a int64
 type Integer struct {
 	Value int64
@@ -85,8 +140,10 @@ func (i *Integer) Type() Type {
 // CanApply for this type
 func (i *Integer) CanApply(op token.Type, t Type) bool {
 	switch t {
-	case IntType, FloatType:
+	case IntType, FloatType, BigIntType, RationalType:
 		return true
+	case DurationType:
+		return op == token.Times || op == token.Equal || op == token.NotEqual
 	default:
 		if op == token.Equal || op == token.NotEqual {
 			return true
@@ -113,13 +170,72 @@ func (f *Float) Type() Type {
 // CanApply for this type
 func (f *Float) CanApply(op token.Type, t Type) bool {
 	switch t {
-	case IntType, FloatType:
+	case IntType, FloatType, BigIntType, RationalType:
 		return true
+	case DurationType:
+		return op == token.Times
 	default:
 		return false
 	}
 }
 
+// BigInt is an arbitrary precision integer
+// Integer arithmetic is promoted to a BigInt when it overflows
+type BigInt struct {
+	Value *big.Int
+}
+
+// String for BigInt
+func (i *BigInt) String() string {
+	return i.Value.String()
+}
+
+// Type for BigInt
+func (i *BigInt) Type() Type {
+	return BigIntType
+}
+
+// CanApply for this type
+func (i *BigInt) CanApply(op token.Type, t Type) bool {
+	switch t {
+	case IntType, FloatType, BigIntType, RationalType:
+		return true
+	default:
+		if op == token.Equal || op == token.NotEqual {
+			return true
+		}
+		return false
+	}
+}
+
+// Rational is an exact fraction
+type Rational struct {
+	Value *big.Rat
+}
+
+// String for Rational
+func (r *Rational) String() string {
+	return r.Value.RatString()
+}
+
+// Type for Rational
+func (r *Rational) Type() Type {
+	return RationalType
+}
+
+// CanApply for this type
+func (r *Rational) CanApply(op token.Type, t Type) bool {
+	switch t {
+	case IntType, FloatType, BigIntType, RationalType:
+		return true
+	default:
+		if op == token.Equal || op == token.NotEqual {
+			return true
+		}
+		return false
+	}
+}
+
 // Boolean is a int64
 type Boolean struct {
 	Value bool
@@ -236,23 +352,25 @@ func (e *Error) CanApply(op token.Type, t Type) bool {
 
 // Function contains a function and current environment
 type Function struct {
-	Params []*ast.Identifier
-	Body   *ast.BlockStatement
-	Env    *Environment
+	Params    []ast.Expression
+	Defaults  []ast.Expression
+	Rest      *ast.Identifier
+	Body      *ast.BlockStatement
+	Env       *Environment
+	Generator bool // calling it makes an iterator over what it yields
+}
+
+// Signature for Function. e.g. |a, b = 10, ...rest|
+func (f *Function) Signature() string {
+	return ast.Signature(f.Params, f.Defaults, f.Rest)
 }
 
 // String for Function
 func (f *Function) String() string {
 	var b bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Params {
-		params = append(params, p.String())
-	}
-
-	b.WriteByte('|')
-	b.WriteString(strings.Join(params, ", "))
-	b.WriteString("| {\n")
+	b.WriteString(f.Signature())
+	b.WriteString(" {\n")
 	b.WriteString(f.Body.String())
 	b.WriteString("\n}")
 
@@ -264,13 +382,28 @@ func (f *Function) Type() Type {
 	return FunctionType
 }
 
+// Members of a Function are the variables of the Environment it closes over
+func (f *Function) Members() *Environment {
+	return f.Env
+}
+
 // CanApply for this type
 func (f *Function) CanApply(op token.Type, t Type) bool {
 	return false
 }
 
+// Caller is how builtins reach the interpreter that called them
+type Caller interface {
+	// Call calls a function object with args so builtins can call back into dusk
+	Call(fn Object, args ...Object) Object
+	// Env is the Environment the builtin was called from
+	Env() *Environment
+	// Stop is closed when the interpreter is stopped
+	Stop() <-chan struct{}
+}
+
 // BuiltinFunction is a function with n args
-type BuiltinFunction func(args ...Object) Object
+type BuiltinFunction func(call Caller, args ...Object) Object
 
 // Builtin is a builtin go function
 type Builtin struct {
@@ -292,9 +425,10 @@ func (b *Builtin) CanApply(op token.Type, t Type) bool {
 	return false
 }
 
-// Array holds n objects
+// Array holds n objects. it isn't locked so spawned functions shouldn't change a shared one
 type Array struct {
 	Elements []Object
+	Frozen   bool // frozen arrays cannot be modified
 }
 
 // String for Array
@@ -332,3 +466,133 @@ func (a *Array) CanApply(op token.Type, t Type) bool {
 		return false
 	}
 }
+
+// Class is a declaration of fields and methods for instances
+type Class struct {
+	Name   string
+	Parent *Class
+	Fields []*ast.LetStatement
+	Env    *Environment // the Environment the class was declared in
+}
+
+// String for Class
+func (c *Class) String() string {
+	if c.Parent != nil {
+		return fmt.Sprintf("class %s < %s", c.Name, c.Parent.Name)
+	}
+	return fmt.Sprintf("class %s", c.Name)
+}
+
+// Type for Class
+func (c *Class) Type() Type {
+	return ClassType
+}
+
+// CanApply for this type
+func (c *Class) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// FieldNames of the class and its parents in the order they are declared
+func (c *Class) FieldNames() []string {
+	names := []string{}
+	if c.Parent != nil {
+		names = c.Parent.FieldNames()
+	}
+
+	for _, f := range c.Fields {
+		found := false
+		for _, n := range names {
+			if n == f.Name.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, f.Name.Value)
+		}
+	}
+
+	return names
+}
+
+// Is reports whether c is other or inherits from it
+func (c *Class) Is(other *Class) bool {
+	for ; c != nil; c = c.Parent {
+		if c == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Instance is an object created by calling a Class
+type Instance struct {
+	Class  *Class
+	Fields *Environment
+}
+
+// String for Instance. shows the fields that are not methods
+func (i *Instance) String() string {
+	var b bytes.Buffer
+
+	fields := []string{}
+	for _, name := range i.Class.FieldNames() {
+		if v, ok := i.Fields.Get(name); ok && v.Type() != FunctionType {
+			fields = append(fields, name+": "+v.String())
+		}
+	}
+
+	b.WriteString(i.Class.Name)
+	b.WriteString("{")
+	b.WriteString(strings.Join(fields, ", "))
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// Type for Instance
+func (i *Instance) Type() Type {
+	return InstanceType
+}
+
+// CanApply for this type
+func (i *Instance) CanApply(op token.Type, t Type) bool {
+	switch op {
+	case token.Equal, token.NotEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Members of an Instance are its fields and methods
+func (i *Instance) Members() *Environment {
+	return i.Fields
+}
+
+// Module is a namespace of builtins and constants such as math
+type Module struct {
+	Name string
+	Env  *Environment
+}
+
+// String for Module
+func (m *Module) String() string {
+	return "module " + m.Name
+}
+
+// Type for Module
+func (m *Module) Type() Type {
+	return ModuleType
+}
+
+// CanApply for this type
+func (m *Module) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// Members of a Module are its builtins and constants
+func (m *Module) Members() *Environment {
+	return m.Env
+}
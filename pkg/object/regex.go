@@ -0,0 +1,32 @@
+package object
+
+import (
+	"jacob/dusk/pkg/token"
+	"regexp"
+)
+
+// Regex is a compiled regular expression. its methods are members bound to it
+type Regex struct {
+	Value *regexp.Regexp
+	Env   *Environment
+}
+
+// String for Regex
+func (r *Regex) String() string {
+	return "re('" + r.Value.String() + "')"
+}
+
+// Type for Regex
+func (r *Regex) Type() Type {
+	return RegexType
+}
+
+// CanApply for this type
+func (r *Regex) CanApply(op token.Type, t Type) bool {
+	return false
+}
+
+// Members of a Regex are its methods
+func (r *Regex) Members() *Environment {
+	return r.Env
+}
@@ -0,0 +1,78 @@
+package object
+
+import (
+	"jacob/dusk/pkg/token"
+	"time"
+)
+
+// Time is an instant in time. its parts such as year and month are members
+type Time struct {
+	Value time.Time
+	Env   *Environment
+}
+
+// String for Time
+func (t *Time) String() string {
+	return t.Value.Format("2006-01-02 15:04:05.999999999 -0700 MST")
+}
+
+// Type for Time
+func (t *Time) Type() Type {
+	return TimeType
+}
+
+// CanApply for this type
+func (t *Time) CanApply(op token.Type, other Type) bool {
+	switch op {
+	case token.Plus:
+		return other == DurationType
+	case token.Minus:
+		return other == DurationType || other == TimeType
+	case token.Less, token.Greater, token.Equal, token.NotEqual:
+		return other == TimeType
+	default:
+		return false
+	}
+}
+
+// Members of a Time are its parts
+func (t *Time) Members() *Environment {
+	return t.Env
+}
+
+// Duration is the time between two instants
+type Duration struct {
+	Value time.Duration
+	Env   *Environment
+}
+
+// String for Duration e.g 1h30m0s
+func (d *Duration) String() string {
+	return d.Value.String()
+}
+
+// Type for Duration
+func (d *Duration) Type() Type {
+	return DurationType
+}
+
+// CanApply for this type
+func (d *Duration) CanApply(op token.Type, other Type) bool {
+	switch op {
+	case token.Plus:
+		return other == DurationType || other == TimeType
+	case token.Minus, token.Less, token.Greater, token.Equal, token.NotEqual:
+		return other == DurationType
+	case token.Times:
+		return other == IntType || other == FloatType
+	case token.Divide:
+		return other == IntType || other == FloatType || other == DurationType
+	default:
+		return false
+	}
+}
+
+// Members of a Duration are its length in different units
+func (d *Duration) Members() *Environment {
+	return d.Env
+}
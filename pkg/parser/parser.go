@@ -28,6 +28,10 @@ const (
 	equals                // == !=
 	inequality            // < >
 	assign                // =
+	bitOr                 // |
+	bitXor                // ~
+	bitAnd                // &
+	shift                 // << >>
 	sum                   // + -
 	product               // * /
 	exp                   // ^ %
@@ -53,6 +57,17 @@ var precedences = map[token.Type]precedence{
 	token.Inc:      assign,
 	token.Dec:      assign,
 	token.LBracket: index,
+
+	token.MulAssign: assign,
+	token.DivAssign: assign,
+	token.ModAssign: assign,
+	token.ExpAssign: assign,
+
+	token.Bar:        bitOr,
+	token.Tilde:      bitXor,
+	token.BitAnd:     bitAnd,
+	token.ShiftLeft:  shift,
+	token.ShiftRight: shift,
 }
 
 // Parser parses into a ast from the lexer
@@ -64,6 +79,9 @@ type Parser struct {
 
 	errors []Error
 
+	// fn is the function literal being parsed so yield can mark it as a generator
+	fn *ast.FunctionLiteral
+
 	prefixParseFns map[token.Type]prefixParseFn
 	infixParseFn   map[token.Type]infixParseFn
 }
@@ -78,15 +96,20 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.Float, p.parseFloatLiteral)
 	p.registerPrefix(token.Bang, p.parsePrefixExpression)
 	p.registerPrefix(token.Minus, p.parsePrefixExpression)
+	p.registerPrefix(token.Tilde, p.parsePrefixExpression)
 	p.registerPrefix(token.True, p.parseBooleanExpression)
 	p.registerPrefix(token.False, p.parseBooleanExpression)
 	p.registerPrefix(token.Nil, p.parseNilExpression)
 	p.registerPrefix(token.LParen, p.parseGroupedExpression)
 	p.registerPrefix(token.If, p.parseIfExpression)
 	p.registerPrefix(token.While, p.parseWhileExpression)
+	p.registerPrefix(token.For, p.parseForExpression)
+	p.registerPrefix(token.Match, p.parseMatchExpression)
 	p.registerPrefix(token.Bar, p.parseFunctionLiteral)
 	p.registerPrefix(token.String, p.parseStringLiteral)
 	p.registerPrefix(token.LBracket, p.parseArrayLiteral)
+	p.registerPrefix(token.Ellipsis, p.parseSpreadExpression)
+	p.registerPrefix(token.Yield, p.parseYieldExpression)
 
 	p.infixParseFn = make(map[token.Type]infixParseFn)
 	p.registerInfix(token.Plus, p.parseInfixExpression)
@@ -98,10 +121,21 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.Equal, p.parseInfixExpression)
 	p.registerInfix(token.Inc, p.parseInfixExpression)
 	p.registerInfix(token.Dec, p.parseInfixExpression)
+	p.registerInfix(token.MulAssign, p.parseInfixExpression)
+	p.registerInfix(token.DivAssign, p.parseInfixExpression)
+	p.registerInfix(token.ModAssign, p.parseInfixExpression)
+	p.registerInfix(token.ExpAssign, p.parseInfixExpression)
 	p.registerInfix(token.NotEqual, p.parseInfixExpression)
 	p.registerInfix(token.Assign, p.parseInfixExpression)
 	p.registerInfix(token.Less, p.parseInfixExpression)
 	p.registerInfix(token.Greater, p.parseInfixExpression)
+	p.registerInfix(token.BitAnd, p.parseInfixExpression)
+	p.registerInfix(token.Tilde, p.parseInfixExpression)
+	p.registerInfix(token.ShiftLeft, p.parseInfixExpression)
+	p.registerInfix(token.ShiftRight, p.parseInfixExpression)
+
+	// '|' opens a function literal as a prefix and is bitwise or as an infix
+	p.registerInfix(token.Bar, p.parseInfixExpression)
 	p.registerInfix(token.LParen, p.parseCallExpression)
 	p.registerInfix(token.Bang, p.parseCallExpression)
 	p.registerInfix(token.LBracket, p.parseIndexExpression)
@@ -177,10 +211,12 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.current.Type {
-	case token.Let:
+	case token.Let, token.Const:
 		return p.parseLetStatement()
 	case token.Return:
 		return p.parseReturnStatement()
+	case token.Class:
+		return p.parseClassStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -189,11 +225,19 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	let := &ast.LetStatement{Token: p.current}
 
-	if !p.expectNext(token.Identifier) {
-		return nil
-	}
+	// let [a, b, ...rest] = expr
+	if p.nextIs(token.LBracket) {
+		p.nextToken()
+		if let.Pattern = p.parseArrayPattern(p.parsePattern); let.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectNext(token.Identifier) {
+			return nil
+		}
 
-	let.Name = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+		let.Name = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	}
 
 	if !p.expectNext(token.Assign) {
 		return nil
@@ -210,6 +254,50 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return let
 }
 
+func (p *Parser) parseClassStatement() ast.Statement {
+	class := &ast.ClassStatement{Token: p.current}
+
+	if !p.expectNext(token.Identifier) {
+		return nil
+	}
+	class.Name = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+
+	// optional '< Parent'
+	if p.nextIs(token.Less) {
+		p.nextToken()
+		if !p.expectNext(token.Identifier) {
+			return nil
+		}
+		class.Parent = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	}
+
+	if !p.expectNext(token.LBrace) {
+		return nil
+	}
+
+	// body can only declare fields and methods
+	errs := len(p.errors)
+	body := p.parseBlockStatement()
+	if len(p.errors) > errs {
+		return nil
+	}
+
+	for _, s := range body.Statements {
+		let, ok := s.(*ast.LetStatement)
+		if !ok || let.Name == nil {
+			p.newError(fmt.Sprintf("class '%s' body can only contain 'let name = value' or 'const name = value' statements. got '%s'", class.Name, s))
+			return nil
+		}
+		class.Fields = append(class.Fields, let)
+	}
+
+	if p.nextIs(token.Terminator) {
+		p.nextToken()
+	}
+
+	return class
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	ret := &ast.ReturnStatement{Token: p.current}
 
@@ -272,29 +360,6 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 }
 
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-	// += and -= operators patch ast in real time
-	// expand a += 3 to a = a + 3
-	if p.current.Type == token.Inc || p.current.Type == token.Dec {
-
-		// top level is a = (...)
-		expr := &ast.InfixExpression{Token: token.Token{Type: token.Assign, Literal: token.Assign.String(), Pos: p.current.Pos}, Left: left, Operator: token.Assign}
-
-		expanded := token.Plus
-		if p.current.Type == token.Dec {
-			expanded = token.Minus
-		}
-
-		// next level is ... = a + expr..
-		exprPlus := &ast.InfixExpression{Token: token.Token{Type: expanded, Literal: expanded.String(), Pos: p.current.Pos}, Left: left, Operator: expanded}
-		expr.Right = exprPlus
-
-		prec := p.currentPrecedence()
-		p.nextToken()
-		exprPlus.Right = p.parseExpression(prec)
-
-		return expr
-	}
-
 	expr := &ast.InfixExpression{Token: p.current, Left: left, Operator: p.current.Type}
 
 	prec := p.currentPrecedence()
@@ -370,11 +435,203 @@ func (p *Parser) parseWhileExpression() ast.Expression {
 	return expr
 }
 
+func (p *Parser) parseForExpression() ast.Expression {
+	expr := &ast.ForExpression{Token: p.current}
+
+	p.nextToken()
+	if expr.Var = p.parsePattern(); expr.Var == nil {
+		return nil
+	}
+
+	// 'in' is not a keyword so it can still be used as the name of the file builtin
+	if !p.nextIs(token.Identifier) || p.next.Literal != "in" {
+		p.newError(fmt.Sprintf("expected 'in' following for variable, got '%s' instead", p.next.Literal))
+		return nil
+	}
+	p.nextToken()
+
+	p.nextToken()
+	expr.Iter = p.parseExpression(lowest)
+
+	// check if with mult statement or single statement
+	if !(p.nextIs(token.LBrace) || p.nextIs(token.Continue)) {
+		p.newError(fmt.Sprintf("expected '{' or ':' following for statement, got '%s' instead", p.next))
+		return nil
+	}
+
+	// goto the { or : and begin the block statment
+	p.nextToken()
+	expr.Do = p.parseBlockStatement()
+
+	return expr
+}
+
+// parseMatchExpression parses the value to match and the arms in braces
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expr := &ast.MatchExpression{Token: p.current}
+
+	p.nextToken()
+	expr.Value = p.parseExpression(lowest)
+
+	if !p.expectNext(token.LBrace) {
+		return nil
+	}
+
+	for {
+		// arms are separated by ',' or the end of a line
+		for p.nextIs(token.Comma) || p.nextIs(token.Terminator) {
+			p.nextToken()
+		}
+		if p.nextIs(token.RBrace) {
+			p.nextToken()
+			break
+		}
+
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expr.Arms = append(expr.Arms, arm)
+	}
+
+	return expr
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{}
+
+	if arm.Pattern = p.parseMatchPattern(); arm.Pattern == nil {
+		return nil
+	}
+
+	if p.nextIs(token.If) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(lowest)
+	}
+
+	if !p.expectNext(token.Arrow) {
+		return nil
+	}
+	arm.Token = p.current
+
+	// '=>' single statement or '=> {' block
+	if p.nextIs(token.LBrace) {
+		p.nextToken()
+	}
+	arm.Body = p.parseBlockStatement()
+
+	return arm
+}
+
+// parseMatchPattern parses a wildcard '_', binding, literal, range or array pattern
+func (p *Parser) parseMatchPattern() ast.Expression {
+	switch p.current.Type {
+	case token.Identifier:
+		return &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	case token.LBracket:
+		if pattern := p.parseArrayPattern(p.parseMatchPattern); pattern != nil {
+			return pattern
+		}
+		return nil
+	}
+
+	low := p.parseLiteralPattern()
+	if low == nil || !p.nextIs(token.Range) {
+		return low
+	}
+
+	p.nextToken()
+	pattern := &ast.RangePattern{Token: p.current, Low: low}
+	p.nextToken()
+	if pattern.High = p.parseLiteralPattern(); pattern.High == nil {
+		return nil
+	}
+
+	return pattern
+}
+
+// parseLiteralPattern parses a number, string, boolean or nil literal. numbers can be negative
+func (p *Parser) parseLiteralPattern() ast.Expression {
+	switch p.current.Type {
+	case token.Int, token.Float, token.String, token.True, token.False, token.Nil:
+		return p.prefixParseFns[p.current.Type]()
+	case token.Minus:
+		if p.nextIs(token.Int) || p.nextIs(token.Float) {
+			return p.parsePrefixExpression()
+		}
+	}
+
+	p.newError(fmt.Sprintf("invalid match pattern '%s'", p.current.Literal))
+	return nil
+}
+
+// parsePattern parses a binding target. either a name or an array pattern
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.current.Type {
+	case token.Identifier:
+		return &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	case token.LBracket:
+		if pattern := p.parseArrayPattern(p.parsePattern); pattern != nil {
+			return pattern
+		}
+		return nil
+	default:
+		p.newError(fmt.Sprintf("expected identifier or '[', got '%s' instead", p.current))
+		return nil
+	}
+}
+
+// parseArrayPattern parses each element with parseElem
+func (p *Parser) parseArrayPattern(parseElem func() ast.Expression) *ast.ArrayPattern {
+	pattern := &ast.ArrayPattern{Token: p.current}
+
+	// '[]' empty pattern
+	if p.nextIs(token.RBracket) {
+		p.nextToken()
+		return pattern
+	}
+
+	for {
+		p.nextToken()
+
+		// '...' or '...name' must be last
+		if p.currentIs(token.Ellipsis) {
+			pattern.HasRest = true
+			if p.nextIs(token.Identifier) {
+				p.nextToken()
+				pattern.Rest = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+			}
+			break
+		}
+
+		elem := parseElem()
+		if elem == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, elem)
+
+		if !p.nextIs(token.Comma) {
+			break
+		}
+		// swollow comma
+		p.nextToken()
+	}
+
+	// must end with ]
+	if !p.expectNext(token.RBracket) {
+		return nil
+	}
+
+	return pattern
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	f := &ast.FunctionLiteral{Token: p.current}
 
 	// current is | or !
-	f.Params = p.parseFunctionParams()
+	p.parseFunctionParams(f)
 
 	// current is ending |
 	// optional {
@@ -383,49 +640,85 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	}
 
 	// if current is not { function will be single statment
+	outer := p.fn
+	p.fn = f
 	f.Body = p.parseBlockStatement()
+	p.fn = outer
 
 	return f
 }
 
-func (p *Parser) parseFunctionParams() []*ast.Identifier {
-	ids := []*ast.Identifier{}
+// parseYieldExpression makes the function it's in a generator
+func (p *Parser) parseYieldExpression() ast.Expression {
+	if p.fn == nil {
+		p.newError("yield can only be used inside a function")
+		return nil
+	}
+	p.fn.Generator = true
+
+	expr := &ast.YieldExpression{Token: p.current}
+	p.nextToken()
+	expr.Value = p.parseExpression(lowest)
+
+	return expr
+}
+
+func (p *Parser) parseFunctionParams(f *ast.FunctionLiteral) {
+	f.Params = []ast.Expression{}
 
 	// capture empty args ! and just return
 	if p.currentIs(token.Bang) {
-		return ids
+		return
 	}
 
 	// '||' empty params
 	if p.nextIs(token.Bar) {
 		p.nextToken()
-		return ids
+		return
 	}
 
-	// that means at least one param. get it
-	if !p.expectNext(token.Identifier) {
-		return nil
-	}
-	ids = append(ids, &ast.Identifier{Token: p.current, Value: p.current.Literal})
-
-	// keep getting params until no more commas
-	for p.nextIs(token.Comma) {
-		// swollow comma
+	for {
 		p.nextToken()
 
-		// param must be id
-		if !p.expectNext(token.Identifier) {
-			return nil
+		// '...name' collects the rest of the args and must be last
+		if p.currentIs(token.Ellipsis) {
+			if !p.expectNext(token.Identifier) {
+				return
+			}
+			f.Rest = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+			break
 		}
-		ids = append(ids, &ast.Identifier{Token: p.current, Value: p.current.Literal})
-	}
 
-	// must end with bar
-	if !p.expectNext(token.Bar) {
-		return nil
+		// param must be id or pattern
+		param := p.parsePattern()
+		if param == nil {
+			return
+		}
+
+		// optional default value. parsed above '|' so it does not close the params
+		var def ast.Expression
+		if p.nextIs(token.Assign) {
+			p.nextToken()
+			p.nextToken()
+			def = p.parseExpression(bitOr)
+		} else if len(f.Defaults) > 0 && f.Defaults[len(f.Defaults)-1] != nil {
+			p.newError(fmt.Sprintf("parameter '%s' without a default cannot follow a parameter with a default", param))
+			return
+		}
+
+		f.Params = append(f.Params, param)
+		f.Defaults = append(f.Defaults, def)
+
+		// keep getting params until no more commas
+		if !p.nextIs(token.Comma) {
+			break
+		}
+		// swollow comma
+		p.nextToken()
 	}
 
-	return ids
+	// must end with bar
+	p.expectNext(token.Bar)
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
@@ -488,14 +781,23 @@ func (p *Parser) parseCallArgs() []ast.Expression {
 	p.nextToken()
 
 	// that means at least one arg. get it
-	args = append(args, p.parseExpression(lowest))
+	args = append(args, p.parseCallArg())
 
 	// keep getting params until no more commas
 	for p.nextIs(token.Comma) {
 		// swollow comma
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(lowest))
+		arg := p.parseCallArg()
+
+		// once an arg is named the rest must be named too
+		if _, ok := args[len(args)-1].(*ast.NamedArgument); ok {
+			if _, ok := arg.(*ast.NamedArgument); !ok {
+				p.newError(fmt.Sprintf("positional argument '%s' cannot follow a named argument", arg))
+				return nil
+			}
+		}
+		args = append(args, arg)
 	}
 
 	// must end with )
@@ -506,6 +808,19 @@ func (p *Parser) parseCallArgs() []ast.Expression {
 	return args
 }
 
+// parseCallArg parses either an expression or a named argument 'name: expression'
+func (p *Parser) parseCallArg() ast.Expression {
+	if p.currentIs(token.Identifier) && p.nextIs(token.Continue) {
+		arg := &ast.NamedArgument{Token: p.current, Name: &ast.Identifier{Token: p.current, Value: p.current.Literal}}
+		p.nextToken()
+		p.nextToken()
+		arg.Value = p.parseExpression(lowest)
+		return arg
+	}
+
+	return p.parseExpression(lowest)
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 
 	if p.nextIs(token.Dot) {
@@ -555,6 +870,13 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.current, Value: p.current.Literal}
 }
 
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	expr := &ast.SpreadExpression{Token: p.current}
+	p.nextToken()
+	expr.Value = p.parseExpression(prefix)
+	return expr
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	arr := &ast.ArrayLiteral{Token: p.current}
 	arr.Elements = p.parseListElems()
@@ -591,16 +913,46 @@ func (p *Parser) parseListElems() []ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	expr := &ast.IndexExpression{Token: p.current, Left: left}
+	tok := p.current
 	p.nextToken()
 
-	expr.Index = p.parseExpression(lowest)
+	var index ast.Expression
+	if !p.currentIs(token.Continue) {
+		index = p.parseExpression(lowest)
+
+		if !p.nextIs(token.Continue) {
+			if !p.expectNext(token.RBracket) {
+				return nil
+			}
+			return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+		}
+		p.nextToken()
+	}
+
+	// current is the first ':' of a slice
+	slice := &ast.SliceExpression{Token: tok, Left: left, Start: index}
+	slice.End = p.parseSliceBound()
+
+	if p.nextIs(token.Continue) {
+		p.nextToken()
+		slice.Step = p.parseSliceBound()
+	}
 
 	if !p.expectNext(token.RBracket) {
 		return nil
 	}
 
-	return expr
+	return slice
+}
+
+// parseSliceBound parses the optional expression after a ':' in a slice
+func (p *Parser) parseSliceBound() ast.Expression {
+	if p.nextIs(token.Continue) || p.nextIs(token.RBracket) {
+		return nil
+	}
+
+	p.nextToken()
+	return p.parseExpression(lowest)
 }
 
 func (p *Parser) parseBooleanExpression() ast.Expression {
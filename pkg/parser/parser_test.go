@@ -132,6 +132,50 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestPatternAndArgumentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = c", "let [a, b] = c; "},
+		{"const a = b", "const a = b; "},
+		{"a[1:2]", "(a[1:2])"},
+		{"a[:-1]", "(a[:(-1)])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[b + 1:c:-1]", "(a[(b + 1):c:(-1)])"},
+		{"a[1:2] = b", "((a[1:2]) = b)"},
+		{"const [a, ...b] = c", "const [a, ...b] = c; "},
+		{"let [a, [b, c], ...d] = e", "let [a, [b, c], ...d] = e; "},
+		{"let [a, ...] = e", "let [a, ...] = e; "},
+		{"let [] = e", "let [] = e; "},
+		{"|[a, b], c| a", "|[a, b], c| { a}"},
+		{"for x in xs: x", "for x in xs { x}"},
+		{"|a, b = 1 + 2, ...c| a", "|a, b = (1 + 2), ...c| { a}"},
+		{"|a = b | c| a", "|a = b| { (c | a)}"},
+		{"f(a, ...b, ...[c])", "f(a, ...b, ...[c])"},
+		{"f(a, b: 1 + 2, c: d)", "f(a, b: (1 + 2), c: d)"},
+		{"for [k, v] in pairs { k + v }", "for [k, v] in pairs { (k + v)}"},
+		{"class A { let x = 1; let f = || self.x }", "class A { let x = 1; let f = || { self.x}; }"},
+		{"class B < A { }", "class B < A { }"},
+		{"match x { 1 => a, -2..5 => b; [c, _, ...] if c > 1 => { c } }", "match x { 1 => { a}, (-2)..5 => { b}, [c, _, ...] if (c > 1) => { c}, }"},
+		{"match x {\n\"a\" => 1\n_ => 2\n}", "match x { \"a\" => { 1}, _ => { 2}, }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.WithString(tt.input, "test")
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -422,6 +466,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a | b ~ c & d",
+			"(a | (b ~ (c & d)))",
+		},
+		{
+			"a & b << c + d",
+			"(a & (b << (c + d)))",
+		},
+		{
+			"a >> 1 == ~b | c",
+			"((a >> 1) == ((~b) | c))",
+		},
+		{
+			"a[i] *= b + c",
+			"((a[i]) *= (b + c))",
+		},
+		{
+			"a.b ^= 2",
+			"(a.b ^= 2)",
+		},
+		{
+			"f(|x| x | 1)",
+			"f(|x| { (x | 1)})",
+		},
 	}
 
 	for _, tt := range tests {
@@ -772,6 +840,40 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestYieldParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		generator bool
+	}{
+		{"|a| yield a + 1", "|a| { yield (a + 1)}", true},
+		{"|a| { for x in a: yield x }", "|a| { for x in a { yield x}}", true},
+		{"|a| |b| yield b", "|a| { |b| { yield b}}", false},
+		{"|a| a", "|a| { a}", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.WithString(tt.input, "test")
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+		f := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+		if f.Generator != tt.generator {
+			t.Errorf("wrong generator for %q. expected=%t, got=%t", tt.input, tt.generator, f.Generator)
+		}
+	}
+
+	p := New(lexer.WithString("yield 1", "test"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0].Str != "yield can only be used inside a function" {
+		t.Errorf("expected an error for yield outside a function. got=%v", p.Errors())
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. got=%q", s.TokenLiteral())
@@ -10,10 +10,8 @@ import (
 	"jacob/dusk/pkg/object"
 	"jacob/dusk/pkg/parser"
 	"log"
-	"math/rand"
 	"os"
 	"strings"
-	"time"
 )
 
 const (
@@ -40,11 +38,11 @@ func color(v interface{}, color colorCode) string {
 // Run starts the repl to read and run a line at a time
 func Run(in io.Reader, out io.Writer) bool {
 
-	rand.Seed(time.Now().UTC().UnixNano())
-
 	fmt.Fprint(out, intro)
 
-	scanner := bufio.NewScanner(in)
+	// the read builtins share the repl's reader so typed input isn't lost between them
+	reader := bufio.NewReader(in)
+	eval.InStream = reader
 
 	env := object.NewEnvironment()
 
@@ -53,13 +51,11 @@ func Run(in io.Reader, out io.Writer) bool {
 		lineNum := 1
 		fmt.Fprint(out, lineNum, color(prompt, green))
 
-		if ok := scanner.Scan(); !ok {
+		line, ok := readLine(reader)
+		if !ok {
 			return false
 		}
 
-		// get current line
-		line := scanner.Text()
-
 		switch line {
 		case ":r":
 			return true
@@ -69,27 +65,7 @@ func Run(in io.Reader, out io.Writer) bool {
 			fmt.Fprint(out, intro)
 			continue
 		case "use iter":
-			iter := `let iter = |a| {
-			    let index = 0
-			    let array = a
-			    let item = nil
-
-			    let next = || {
-			        ret if index < len(array) {
-			            item = array[index]
-			            index += 1
-			            ret item
-			        }
-			    }
-
-			    ret || iter
-			}`
-
-			l := lexer.WithString(iter, "iter")
-			p := parser.New(l)
-			program := p.ParseProgram()
-			eval.Eval(program, env, nil)
-
+			fmt.Fprintln(out, "", color(prompt, magneta), "\t", color("iter is a builtin now. there's no need to use it", yellow))
 			continue
 		}
 
@@ -121,11 +97,10 @@ func Run(in io.Reader, out io.Writer) bool {
 				lineNum++
 				fmt.Fprint(out, lineNum, color(prompt, blue), strings.Repeat("\t", indent))
 
-				if ok := scanner.Scan(); !ok {
+				nextLine, ok := readLine(reader)
+				if !ok {
 					return false
 				}
-
-				nextLine := scanner.Text()
 				b.WriteString(nextLine)
 				b.WriteByte('\n')
 
@@ -168,6 +143,15 @@ func Run(in io.Reader, out io.Writer) bool {
 	}
 }
 
+// readLine reads a line without the new line. false at the end of the input
+func readLine(reader *bufio.Reader) (string, bool) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
 func printErrors(out io.Writer, errors []parser.Error) {
 	for _, err := range errors {
 		fmt.Fprintln(out, "", color(prompt, red), "\t", color(fmt.Sprint(err.Pos, ":"), red), err.Str)
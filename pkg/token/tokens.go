@@ -24,6 +24,16 @@ const (
 	Inc     // Inc +=
 	Dec     // Dec -=
 
+	MulAssign // MulAssign *=
+	DivAssign // DivAssign /=
+	ModAssign // ModAssign %=
+	ExpAssign // ExpAssign ^=
+
+	BitAnd     // BitAnd &
+	Tilde      // Tilde ~ - xor when infix, bitwise not when prefix
+	ShiftLeft  // ShiftLeft <<
+	ShiftRight // ShiftRight >>
+
 	Equal    // Equal ==
 	NotEqual // NotEqual !=
 
@@ -39,18 +49,26 @@ const (
 	RBracket // RBracket ]
 
 	Dot      // Dot .
+	Ellipsis // Ellipsis ...
+	Range    // Range ..
+	Arrow    // Arrow => - separates a match pattern from its body
 	Comma    // Comma ,
 	Bar      // Bar |  - donotes function arg bar
 	Continue // Continue - starts a single statment/line block
 
 	Let    // Let keyword
+	Const  // Const keyword
 	If     // If keyword
 	Else   // Else keyword
 	While  // For keyword
+	For    // For keyword
 	Return // Ret keyword
 	True   // True keyword
 	False  // False keyword
 	Nil    // nil keyword
+	Class  // class keyword
+	Match  // match keyword
+	Yield  // yield keyword
 )
 
 // LookupLiteral returns string for type
@@ -79,6 +97,22 @@ func (t Type) String() string {
 		return "+="
 	case Dec:
 		return "-="
+	case MulAssign:
+		return "*="
+	case DivAssign:
+		return "/="
+	case ModAssign:
+		return "%="
+	case ExpAssign:
+		return "^="
+	case BitAnd:
+		return "&"
+	case Tilde:
+		return "~"
+	case ShiftLeft:
+		return "<<"
+	case ShiftRight:
+		return ">>"
 	case Bang:
 		return "!"
 	case Less:
@@ -105,6 +139,12 @@ func (t Type) String() string {
 		return ","
 	case Dot:
 		return "."
+	case Ellipsis:
+		return "..."
+	case Range:
+		return ".."
+	case Arrow:
+		return "=>"
 	case Terminator:
 		return "terminator"
 	case EOF:
@@ -129,10 +169,20 @@ func (t Type) String() string {
 		return "true"
 	case While:
 		return "while"
+	case For:
+		return "for"
 	case Return:
 		return "ret"
 	case Nil:
 		return "nil"
+	case Class:
+		return "class"
+	case Const:
+		return "const"
+	case Match:
+		return "match"
+	case Yield:
+		return "yield"
 	default:
 		return "unknown"
 	}
@@ -146,6 +196,11 @@ var keywords = map[string]Type{
 	"false": False,
 	"true":  True,
 	"while": While,
+	"for":   For,
 	"ret":   Return,
 	"nil":   Nil,
+	"class": Class,
+	"match": Match,
+	"const": Const,
+	"yield": Yield,
 }